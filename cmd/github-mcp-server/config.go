@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configExtensions are the config file formats, in the order they are looked for.
+var configExtensions = []string{"yaml", "yml", "json", "toml"}
+
+// secretConfigKeys are settings only read from the environment, so that they are not written to
+// config files that are easily shared or checked in.
+var secretConfigKeys = []string{"personal_access_token", "oauth_client_secret"}
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration file",
+		Long:  `Inspect the configuration file the server reads its settings from.`,
+		// The subcommands read the config file themselves, to report its problems rather than
+		// failing on them.
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return nil
+		},
+	}
+
+	configValidateCmd = &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Check a configuration file for mistakes",
+		Long:  `Check the configuration file given as argument, set with --config or found in the default locations, and report the keys the server does not know.`,
+		Args:  cobra.MaximumNArgs(1),
+		// The problems are the output, so there is no need for the usage
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := viper.GetString("config")
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if path == "" {
+				found, err := findConfigFile(configSearchDirs())
+				if err != nil {
+					return err
+				}
+				if found == "" {
+					return fmt.Errorf("no config file found in %s", strings.Join(configSearchDirs(), ", "))
+				}
+				path = found
+			}
+
+			// The config file is not read into viper for this command, so its keys are the ones
+			// bound to flags.
+			problems, err := validateConfigFile(path, viper.AllKeys())
+			if err != nil {
+				return err
+			}
+			if len(problems) > 0 {
+				for _, problem := range problems {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s\n", path, problem)
+				}
+				return fmt.Errorf("%s has %d problem(s)", path, len(problems))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", path)
+			return nil
+		},
+	}
+)
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// configSearchDirs returns the directories a config file is looked for in when none is given,
+// following the XDG base directory specification.
+func configSearchDirs() []string {
+	var dirs []string
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "github-mcp-server"))
+	}
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	for _, dir := range filepath.SplitList(configDirs) {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "github-mcp-server"))
+		}
+	}
+	return dirs
+}
+
+// findConfigFile returns the first config file in dirs, or an empty string if there is none.
+func findConfigFile(dirs []string) (string, error) {
+	for _, dir := range dirs {
+		for _, ext := range configExtensions {
+			path := filepath.Join(dir, "config."+ext)
+			_, err := os.Stat(path)
+			if err == nil {
+				return path, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", fmt.Errorf("failed to check for config file: %w", err)
+			}
+		}
+	}
+	return "", nil
+}
+
+// configFiles returns the config file set with --config or GITHUB_CONFIG, or else every file that
+// would be found in the default locations, to be checked for changes.
+func configFiles() []string {
+	if path := viper.GetString("config"); path != "" {
+		return []string{path}
+	}
+	var files []string
+	for _, dir := range configSearchDirs() {
+		for _, ext := range configExtensions {
+			files = append(files, filepath.Join(dir, "config."+ext))
+		}
+	}
+	return files
+}
+
+// readConfigFile reads the config file set with --config or GITHUB_CONFIG, or else the first one
+// found in the default locations, into viper. Flags and env vars take precedence over its settings.
+func readConfigFile() error {
+	path := viper.GetString("config")
+	if path == "" {
+		found, err := findConfigFile(configSearchDirs())
+		if err != nil {
+			return err
+		}
+		if found == "" {
+			return nil
+		}
+		path = found
+	}
+
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	for _, key := range secretConfigKeys {
+		if viper.InConfig(key) {
+			return fmt.Errorf("config file %s must not contain %s, set GITHUB_%s instead", path, key, strings.ToUpper(key))
+		}
+	}
+	return nil
+}
+
+// validateConfigFile reads the config file at path and returns its problems: settings that are
+// not among knownKeys, and secrets that must be set in the environment.
+func validateConfigFile(path string, knownKeys []string) ([]string, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	settings := v.AllSettings()
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		switch {
+		case slices.Contains(secretConfigKeys, key):
+			problems = append(problems, fmt.Sprintf("%s must not be set in a config file, set GITHUB_%s instead", key, strings.ToUpper(key)))
+		case key == "descriptions":
+			if _, ok := settings[key].(map[string]any); !ok {
+				problems = append(problems, "descriptions must be a map of description keys to descriptions")
+			}
+		case key == "config" || !slices.Contains(knownKeys, key):
+			problems = append(problems, fmt.Sprintf("unknown key %q", key))
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FindConfigFile(t *testing.T) {
+	user, system := t.TempDir(), t.TempDir()
+
+	path, err := findConfigFile([]string{user, system})
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(system, "config.toml"), nil, 0600))
+	path, err = findConfigFile([]string{user, system})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(system, "config.toml"), path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(user, "config.json"), nil, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(user, "config.yaml"), nil, 0600))
+	path, err = findConfigFile([]string{user, system})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(user, "config.yaml"), path, "the user config and YAML should be preferred")
+}
+
+func Test_ValidateConfigFile(t *testing.T) {
+	knownKeys := []string{"config", "host", "toolsets", "read_only"}
+
+	tests := []struct {
+		name             string
+		file             string
+		content          string
+		expectedProblems []string
+		expectedErrMsg   string
+	}{
+		{
+			name: "valid yaml",
+			file: "config.yaml",
+			content: `host: https://github.example.com
+toolsets: [repos, issues]
+read_only: true
+descriptions:
+  TOOL_CREATE_ISSUE_DESCRIPTION: Open an issue
+`,
+		},
+		{
+			name: "valid toml",
+			file: "config.toml",
+			content: `host = "https://github.example.com"
+toolsets = ["repos"]
+`,
+		},
+		{
+			name:    "unknown keys and secrets",
+			file:    "config.json",
+			content: `{"read-only": true, "personal_access_token": "ghp_test", "config": "other.yaml", "descriptions": "none"}`,
+			expectedProblems: []string{
+				`unknown key "config"`,
+				"descriptions must be a map of description keys to descriptions",
+				"personal_access_token must not be set in a config file, set GITHUB_PERSONAL_ACCESS_TOKEN instead",
+				`unknown key "read-only"`,
+			},
+		},
+		{
+			name:           "syntax error",
+			file:           "config.yaml",
+			content:        "toolsets: [repos\n",
+			expectedErrMsg: "failed to read config file",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0600))
+
+			problems, err := validateConfigFile(path, knownKeys)
+			if tc.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedProblems, problems)
+		})
+	}
+}
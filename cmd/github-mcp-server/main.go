@@ -4,9 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/github/github-mcp-server/internal/ghmcp"
+	"github.com/github/github-mcp-server/pkg/audit"
+	"github.com/github/github-mcp-server/pkg/budget"
 	"github.com/github/github-mcp-server/pkg/github"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -24,6 +28,9 @@ var (
 		Short:   "GitHub MCP Server",
 		Long:    `A GitHub MCP server that handles various tools and resources.`,
 		Version: fmt.Sprintf("Version: %s\nCommit: %s\nBuild Date: %s", version, commit, date),
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return readConfigFile()
+		},
 	}
 
 	stdioCmd = &cobra.Command{
@@ -31,9 +38,25 @@ var (
 		Short: "Start stdio server",
 		Long:  `Start a server that communicates via standard input/output streams using JSON-RPC messages.`,
 		RunE: func(_ *cobra.Command, _ []string) error {
+			gitHubApp := gitHubAppConfig()
+			if err := gitHubApp.Validate(); err != nil {
+				return err
+			}
+
+			oauth, err := oauthConfig()
+			if err != nil {
+				return err
+			}
+
 			token := viper.GetString("personal_access_token")
-			if token == "" {
-				return errors.New("GITHUB_PERSONAL_ACCESS_TOKEN not set")
+			if token == "" && !gitHubApp.Enabled() {
+				loggedIn, err := ghmcp.HasStoredCredentials(viper.GetString("host"), oauth.CredentialsPath)
+				if err != nil {
+					return err
+				}
+				if !loggedIn {
+					return errors.New("GITHUB_PERSONAL_ACCESS_TOKEN not set, set it or run the login command")
+				}
 			}
 
 			// If you're wondering why we're not using viper.GetStringSlice("toolsets"),
@@ -45,20 +68,196 @@ var (
 				return fmt.Errorf("failed to unmarshal toolsets: %w", err)
 			}
 
+			tools, excludeTools, err := toolFilters()
+			if err != nil {
+				return err
+			}
+
+			auditCfg, err := auditConfig()
+			if err != nil {
+				return err
+			}
+
+			hosts, err := hostsConfig()
+			if err != nil {
+				return err
+			}
+
+			network, err := networkConfig()
+			if err != nil {
+				return err
+			}
+
+			outputBudget, err := budgetConfig()
+			if err != nil {
+				return err
+			}
+
+			outputFormat, err := github.ParseOutputFormat(viper.GetString("output_format"))
+			if err != nil {
+				return err
+			}
+
 			stdioServerConfig := ghmcp.StdioServerConfig{
 				Version:              version,
 				Host:                 viper.GetString("host"),
+				HostOverrides:        apiHostOverrides(),
 				Token:                token,
+				GitHubApp:            gitHubApp,
+				OAuth:                oauth,
 				EnabledToolsets:      enabledToolsets,
+				Tools:                tools,
+				ExcludeTools:         excludeTools,
 				DynamicToolsets:      viper.GetBool("dynamic_toolsets"),
-				ReadOnly:             viper.GetBool("read-only"),
-				ExportTranslations:   viper.GetBool("export-translations"),
-				EnableCommandLogging: viper.GetBool("enable-command-logging"),
-				LogFilePath:          viper.GetString("log-file"),
+				ReadOnly:             viper.GetBool("read_only"),
+				DryRun:               viper.GetBool("dry_run"),
+				ExportTranslations:   viper.GetBool("export_translations"),
+				EnableCommandLogging: viper.GetBool("enable_command_logging"),
+				LogFilePath:          viper.GetString("log_file"),
+				RateLimit:            rateLimitConfig(),
+				Cache:                cacheConfig(),
+				MetricsAddress:       viper.GetString("metrics_address"),
+				Tracing:              ghmcp.TracingConfig{Endpoint: viper.GetString("otlp_endpoint")},
+				Audit:                auditCfg,
+				PolicyPath:           viper.GetString("repository_policy"),
+				Descriptions:         viper.GetStringMapString("descriptions"),
+				Reload:               reloadConfig(),
+				Hosts:                hosts,
+				Network:              network,
+				Budget:               outputBudget,
+				OutputFormat:         outputFormat,
 			}
 			return ghmcp.RunStdioServer(stdioServerConfig)
 		},
 	}
+
+	httpCmd = &cobra.Command{
+		Use:   "http",
+		Short: "Start streamable HTTP server",
+		Long:  `Start a server that communicates via the MCP streamable HTTP transport, suitable for sharing between several clients.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			gitHubApp := gitHubAppConfig()
+			if err := gitHubApp.Validate(); err != nil {
+				return err
+			}
+
+			// The token is optional here, as each request may carry its own in the Authorization header.
+			token := viper.GetString("personal_access_token")
+
+			// See the comment in stdioCmd for why we're not using viper.GetStringSlice("toolsets").
+			var enabledToolsets []string
+			if err := viper.UnmarshalKey("toolsets", &enabledToolsets); err != nil {
+				return fmt.Errorf("failed to unmarshal toolsets: %w", err)
+			}
+
+			tools, excludeTools, err := toolFilters()
+			if err != nil {
+				return err
+			}
+
+			auditCfg, err := auditConfig()
+			if err != nil {
+				return err
+			}
+
+			hosts, err := hostsConfig()
+			if err != nil {
+				return err
+			}
+
+			network, err := networkConfig()
+			if err != nil {
+				return err
+			}
+
+			outputBudget, err := budgetConfig()
+			if err != nil {
+				return err
+			}
+
+			outputFormat, err := github.ParseOutputFormat(viper.GetString("output_format"))
+			if err != nil {
+				return err
+			}
+
+			httpServerConfig := ghmcp.HTTPServerConfig{
+				Version:            version,
+				Host:               viper.GetString("host"),
+				HostOverrides:      apiHostOverrides(),
+				Token:              token,
+				GitHubApp:          gitHubApp,
+				AllowServerToken:   viper.GetBool("http_allow_server_token"),
+				EnabledToolsets:    enabledToolsets,
+				Tools:              tools,
+				ExcludeTools:       excludeTools,
+				DynamicToolsets:    viper.GetBool("dynamic_toolsets"),
+				ReadOnly:           viper.GetBool("read_only"),
+				DryRun:             viper.GetBool("dry_run"),
+				ExportTranslations: viper.GetBool("export_translations"),
+				LogFilePath:        viper.GetString("log_file"),
+				RateLimit:          rateLimitConfig(),
+				Cache:              cacheConfig(),
+				MetricsAddress:     viper.GetString("metrics_address"),
+				Tracing:            ghmcp.TracingConfig{Endpoint: viper.GetString("otlp_endpoint")},
+				Audit:              auditCfg,
+				PolicyPath:         viper.GetString("repository_policy"),
+				Descriptions:       viper.GetStringMapString("descriptions"),
+				Reload:             reloadConfig(),
+				Hosts:              hosts,
+				Network:            network,
+				Budget:             outputBudget,
+				OutputFormat:       outputFormat,
+				Address:            viper.GetString("http_address"),
+			}
+			return ghmcp.RunHTTPServer(httpServerConfig)
+		},
+	}
+
+	loginCmd = &cobra.Command{
+		Use:   "login",
+		Short: "Log in to GitHub with the OAuth device flow",
+		Long:  `Log in to the GitHub host with the OAuth device flow and store the token in the credential file, to be used by the stdio server when no token is set.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			oauth, err := oauthConfig()
+			if err != nil {
+				return err
+			}
+
+			var scopes []string
+			if err := viper.UnmarshalKey("oauth_scopes", &scopes); err != nil {
+				return fmt.Errorf("failed to unmarshal OAuth scopes: %w", err)
+			}
+			oauth.Scopes = scopes
+
+			network, err := networkConfig()
+			if err != nil {
+				return err
+			}
+
+			return ghmcp.RunLogin(ghmcp.LoginConfig{
+				Host:    viper.GetString("host"),
+				OAuth:   oauth,
+				Network: network,
+			})
+		},
+	}
+
+	logoutCmd = &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the stored GitHub credentials",
+		Long:  `Remove the credentials stored for the GitHub host by the login command.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			oauth, err := oauthConfig()
+			if err != nil {
+				return err
+			}
+
+			return ghmcp.RunLogout(ghmcp.LogoutConfig{
+				Host:            viper.GetString("host"),
+				CredentialsPath: oauth.CredentialsPath,
+			})
+		},
+	}
 )
 
 func init() {
@@ -68,32 +267,311 @@ func init() {
 	rootCmd.SetVersionTemplate("{{.Short}}\n{{.Version}}\n")
 
 	// Add global flags that will be shared by all commands
+	rootCmd.PersistentFlags().String("config", "", "Path to a YAML, JSON or TOML config file, defaults to config.yaml in the github-mcp-server user config directory")
 	rootCmd.PersistentFlags().StringSlice("toolsets", github.DefaultTools, "An optional comma separated list of groups of tools to allow, defaults to enabling all")
+	rootCmd.PersistentFlags().StringSlice("tools", nil, "An optional comma separated list of tools to allow within the enabled toolsets, defaults to all of their tools")
+	rootCmd.PersistentFlags().StringSlice("exclude-tools", nil, "An optional comma separated list of tools to leave out of the enabled toolsets")
 	rootCmd.PersistentFlags().Bool("dynamic-toolsets", false, "Enable dynamic toolsets")
 	rootCmd.PersistentFlags().Bool("read-only", false, "Restrict the server to read-only operations")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Report the changes write tools would make to GitHub instead of making them")
 	rootCmd.PersistentFlags().String("log-file", "", "Path to log file")
 	rootCmd.PersistentFlags().Bool("enable-command-logging", false, "When enabled, the server will log all command requests and responses to the log file")
 	rootCmd.PersistentFlags().Bool("export-translations", false, "Save translations to a JSON file")
 	rootCmd.PersistentFlags().String("gh-host", "", "Specify the GitHub hostname (for GitHub Enterprise etc.)")
+	rootCmd.PersistentFlags().String("gh-rest-url", "", "Override the REST API base URL derived from the GitHub host")
+	rootCmd.PersistentFlags().String("gh-graphql-url", "", "Override the GraphQL API URL derived from the GitHub host")
+	rootCmd.PersistentFlags().String("gh-upload-url", "", "Override the upload base URL derived from the GitHub host")
+	rootCmd.PersistentFlags().String("gh-raw-url", "", "Override the raw content base URL derived from the GitHub host")
+	rootCmd.PersistentFlags().StringSlice("ca-bundle", nil, "Comma separated list of PEM files of certificate authorities to trust in addition to the system ones")
+	rootCmd.PersistentFlags().StringSlice("client-cert", nil, "Comma separated list of PEM client certificates for servers that require one, paired with --client-key")
+	rootCmd.PersistentFlags().StringSlice("client-key", nil, "Comma separated list of PEM private keys of the client certificates, in the same order")
+	rootCmd.PersistentFlags().String("proxy", "", "Proxy URL for all requests to GitHub, defaults to the HTTPS_PROXY and HTTP_PROXY env vars")
+	rootCmd.PersistentFlags().StringSlice("no-proxy", nil, "Comma separated list of hosts, domains and CIDR ranges to connect to without the proxy, defaults to the NO_PROXY env var")
+	rootCmd.PersistentFlags().StringSlice("hosts", nil, "An optional comma separated list of additional GitHub hosts as name=https://hostname, each authenticated with GITHUB_PERSONAL_ACCESS_TOKEN_<NAME>")
+	rootCmd.PersistentFlags().Int("rate-limit-max-retries", 3, "Maximum number of times a request rejected by rate limits or transient server errors is retried, 0 disables retries")
+	rootCmd.PersistentFlags().Duration("rate-limit-max-wait", time.Minute, "Maximum total time to wait before retrying a single request, longer waits return the rate limit error instead")
+	rootCmd.PersistentFlags().Int("cache-max-size", 64, "Maximum size in megabytes of cached REST API responses, which are revalidated with conditional requests, 0 disables the cache")
+	rootCmd.PersistentFlags().String("cache-dir", "", "Persist cached REST API responses in this directory instead of in memory")
+	rootCmd.PersistentFlags().String("metrics-address", "", "Address to serve Prometheus metrics on at /metrics (e.g. localhost:9090), disabled if empty")
+	rootCmd.PersistentFlags().String("otlp-endpoint", "", "OTLP/HTTP endpoint URL to export OpenTelemetry traces to (e.g. http://localhost:4318), also enabled by OTEL_EXPORTER_OTLP_ENDPOINT")
+	rootCmd.PersistentFlags().String("audit-log", "", "Append a JSON line to this file for every call of a tool that writes to GitHub, disabled if empty")
+	rootCmd.PersistentFlags().Int("audit-log-max-size", 100, "Size in megabytes at which the audit log is rotated, 0 disables rotation")
+	rootCmd.PersistentFlags().Int("audit-log-max-backups", 5, "Number of rotated audit logs to keep")
+	rootCmd.PersistentFlags().StringSlice("audit-redact", audit.DefaultRedactions, "Comma separated list of arguments left out of the audit log, as argument or tool:argument")
+	rootCmd.PersistentFlags().Int("output-budget", 0, "Size tool results are truncated to, with a continuation token to get the rest, 0 disables truncation")
+	rootCmd.PersistentFlags().String("output-budget-unit", "bytes", "Unit of the output budgets, bytes or tokens (approximated as 4 bytes each)")
+	rootCmd.PersistentFlags().StringSlice("tool-output-budgets", nil, "Comma separated list of tool=size budgets that override --output-budget, 0 disables truncation for a tool")
+	rootCmd.PersistentFlags().String("output-format", "compact", "Default format of JSON tool results: compact, json, yaml or markdown")
+	rootCmd.PersistentFlags().String("repository-policy", "", "Path to a JSON file of owner/repo patterns that tools and resources are allowed or denied access to")
+	rootCmd.PersistentFlags().Duration("reload-interval", 2*time.Second, "Interval at which the config file, repository policy and translations are checked for changes to reload, 0 only reloads on SIGHUP")
+	rootCmd.PersistentFlags().Int64("app-id", 0, "Authenticate as this GitHub App instead of with a personal access token")
+	rootCmd.PersistentFlags().Int64("app-installation-id", 0, "The GitHub App installation to mint installation tokens for")
+	rootCmd.PersistentFlags().String("app-private-key-path", "", "Path to the PEM encoded private key of the GitHub App")
+	rootCmd.PersistentFlags().String("oauth-client-id", "", "Client ID of the OAuth or GitHub App used by the login command")
+	rootCmd.PersistentFlags().String("credentials-file", "", "Path to the credential file written by the login command, defaults to the user config directory")
 
 	// Bind flag to viper
+	_ = viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
 	_ = viper.BindPFlag("toolsets", rootCmd.PersistentFlags().Lookup("toolsets"))
+	_ = viper.BindPFlag("tools", rootCmd.PersistentFlags().Lookup("tools"))
+	_ = viper.BindPFlag("exclude_tools", rootCmd.PersistentFlags().Lookup("exclude-tools"))
 	_ = viper.BindPFlag("dynamic_toolsets", rootCmd.PersistentFlags().Lookup("dynamic-toolsets"))
-	_ = viper.BindPFlag("read-only", rootCmd.PersistentFlags().Lookup("read-only"))
-	_ = viper.BindPFlag("log-file", rootCmd.PersistentFlags().Lookup("log-file"))
-	_ = viper.BindPFlag("enable-command-logging", rootCmd.PersistentFlags().Lookup("enable-command-logging"))
-	_ = viper.BindPFlag("export-translations", rootCmd.PersistentFlags().Lookup("export-translations"))
+	_ = viper.BindPFlag("read_only", rootCmd.PersistentFlags().Lookup("read-only"))
+	_ = viper.BindPFlag("dry_run", rootCmd.PersistentFlags().Lookup("dry-run"))
+	_ = viper.BindPFlag("log_file", rootCmd.PersistentFlags().Lookup("log-file"))
+	_ = viper.BindPFlag("enable_command_logging", rootCmd.PersistentFlags().Lookup("enable-command-logging"))
+	_ = viper.BindPFlag("export_translations", rootCmd.PersistentFlags().Lookup("export-translations"))
 	_ = viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("gh-host"))
+	_ = viper.BindPFlag("rest_url", rootCmd.PersistentFlags().Lookup("gh-rest-url"))
+	_ = viper.BindPFlag("graphql_url", rootCmd.PersistentFlags().Lookup("gh-graphql-url"))
+	_ = viper.BindPFlag("upload_url", rootCmd.PersistentFlags().Lookup("gh-upload-url"))
+	_ = viper.BindPFlag("raw_url", rootCmd.PersistentFlags().Lookup("gh-raw-url"))
+	_ = viper.BindPFlag("ca_bundle", rootCmd.PersistentFlags().Lookup("ca-bundle"))
+	_ = viper.BindPFlag("client_cert", rootCmd.PersistentFlags().Lookup("client-cert"))
+	_ = viper.BindPFlag("client_key", rootCmd.PersistentFlags().Lookup("client-key"))
+	_ = viper.BindPFlag("proxy", rootCmd.PersistentFlags().Lookup("proxy"))
+	_ = viper.BindPFlag("no_proxy", rootCmd.PersistentFlags().Lookup("no-proxy"))
+	_ = viper.BindPFlag("hosts", rootCmd.PersistentFlags().Lookup("hosts"))
+	_ = viper.BindPFlag("rate_limit_max_retries", rootCmd.PersistentFlags().Lookup("rate-limit-max-retries"))
+	_ = viper.BindPFlag("rate_limit_max_wait", rootCmd.PersistentFlags().Lookup("rate-limit-max-wait"))
+	_ = viper.BindPFlag("cache_max_size", rootCmd.PersistentFlags().Lookup("cache-max-size"))
+	_ = viper.BindPFlag("cache_dir", rootCmd.PersistentFlags().Lookup("cache-dir"))
+	_ = viper.BindPFlag("metrics_address", rootCmd.PersistentFlags().Lookup("metrics-address"))
+	_ = viper.BindPFlag("otlp_endpoint", rootCmd.PersistentFlags().Lookup("otlp-endpoint"))
+	_ = viper.BindPFlag("audit_log", rootCmd.PersistentFlags().Lookup("audit-log"))
+	_ = viper.BindPFlag("audit_log_max_size", rootCmd.PersistentFlags().Lookup("audit-log-max-size"))
+	_ = viper.BindPFlag("audit_log_max_backups", rootCmd.PersistentFlags().Lookup("audit-log-max-backups"))
+	_ = viper.BindPFlag("audit_redact", rootCmd.PersistentFlags().Lookup("audit-redact"))
+	_ = viper.BindPFlag("output_budget", rootCmd.PersistentFlags().Lookup("output-budget"))
+	_ = viper.BindPFlag("output_budget_unit", rootCmd.PersistentFlags().Lookup("output-budget-unit"))
+	_ = viper.BindPFlag("tool_output_budgets", rootCmd.PersistentFlags().Lookup("tool-output-budgets"))
+	_ = viper.BindPFlag("output_format", rootCmd.PersistentFlags().Lookup("output-format"))
+	_ = viper.BindPFlag("repository_policy", rootCmd.PersistentFlags().Lookup("repository-policy"))
+	_ = viper.BindPFlag("reload_interval", rootCmd.PersistentFlags().Lookup("reload-interval"))
+	_ = viper.BindPFlag("app_id", rootCmd.PersistentFlags().Lookup("app-id"))
+	_ = viper.BindPFlag("app_installation_id", rootCmd.PersistentFlags().Lookup("app-installation-id"))
+	_ = viper.BindPFlag("app_private_key_path", rootCmd.PersistentFlags().Lookup("app-private-key-path"))
+	_ = viper.BindPFlag("oauth_client_id", rootCmd.PersistentFlags().Lookup("oauth-client-id"))
+	_ = viper.BindPFlag("credentials_file", rootCmd.PersistentFlags().Lookup("credentials-file"))
+
+	// Add http specific flags
+	httpCmd.Flags().String("address", "localhost:8080", "Address to listen on for streamable HTTP requests, use :8080 to listen on every interface")
+	httpCmd.Flags().Bool("allow-server-token", false, "Let requests without an Authorization header act as the server's token or GitHub App")
+	_ = viper.BindPFlag("http_address", httpCmd.Flags().Lookup("address"))
+	_ = viper.BindPFlag("http_allow_server_token", httpCmd.Flags().Lookup("allow-server-token"))
+
+	// Add login specific flags
+	loginCmd.Flags().StringSlice("scopes", []string{"repo", "read:org", "workflow", "notifications"}, "Comma separated list of OAuth scopes to request")
+	_ = viper.BindPFlag("oauth_scopes", loginCmd.Flags().Lookup("scopes"))
 
 	// Add subcommands
 	rootCmd.AddCommand(stdioCmd)
+	rootCmd.AddCommand(httpCmd)
+	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(logoutCmd)
 }
 
 func initConfig() {
 	// Initialize Viper configuration
 	viper.SetEnvPrefix("github")
 	viper.AutomaticEnv()
+}
+
+// toolFilters reads the lists of tools to include and exclude from flags and env vars.
+func toolFilters() (tools, excludeTools []string, err error) {
+	// See the comment in stdioCmd for why we're not using viper.GetStringSlice.
+	if err := viper.UnmarshalKey("tools", &tools); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal tools: %w", err)
+	}
+	if err := viper.UnmarshalKey("exclude_tools", &excludeTools); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal excluded tools: %w", err)
+	}
+	return tools, excludeTools, nil
+}
+
+// reloadConfig configures reloading the settings that can change while the server runs.
+func reloadConfig() ghmcp.ReloadConfig {
+	return ghmcp.ReloadConfig{
+		Load:     reloadableConfig,
+		Files:    configFiles(),
+		Interval: viper.GetDuration("reload_interval"),
+	}
+}
+
+// reloadableConfig reads the settings that can change while the server runs from the config file,
+// env vars and flags.
+func reloadableConfig() (ghmcp.ReloadableConfig, error) {
+	if err := readConfigFile(); err != nil {
+		return ghmcp.ReloadableConfig{}, err
+	}
+
+	// See the comment in stdioCmd for why we're not using viper.GetStringSlice.
+	var enabledToolsets []string
+	if err := viper.UnmarshalKey("toolsets", &enabledToolsets); err != nil {
+		return ghmcp.ReloadableConfig{}, fmt.Errorf("failed to unmarshal toolsets: %w", err)
+	}
+
+	tools, excludeTools, err := toolFilters()
+	if err != nil {
+		return ghmcp.ReloadableConfig{}, err
+	}
+
+	return ghmcp.ReloadableConfig{
+		EnabledToolsets: enabledToolsets,
+		Tools:           tools,
+		ExcludeTools:    excludeTools,
+		PolicyPath:      viper.GetString("repository_policy"),
+		Descriptions:    viper.GetStringMapString("descriptions"),
+	}, nil
+}
+
+// apiHostOverrides reads the explicit API URL overrides from flags and env vars.
+func apiHostOverrides() ghmcp.APIHostOverrides {
+	return ghmcp.APIHostOverrides{
+		RESTURL:    viper.GetString("rest_url"),
+		GraphQLURL: viper.GetString("graphql_url"),
+		UploadURL:  viper.GetString("upload_url"),
+		RawURL:     viper.GetString("raw_url"),
+	}
+}
+
+// hostsConfig reads the additional GitHub hosts from flags and env vars. Their tokens are
+// deliberately only read from env vars.
+func hostsConfig() ([]ghmcp.HostConfig, error) {
+	// See the comment in stdioCmd for why we're not using viper.GetStringSlice.
+	var entries []string
+	if err := viper.UnmarshalKey("hosts", &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hosts: %w", err)
+	}
+
+	hosts := make([]ghmcp.HostConfig, 0, len(entries))
+	for _, entry := range entries {
+		name, host, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || host == "" {
+			return nil, fmt.Errorf("invalid host %q: must be name=https://hostname", entry)
+		}
+		hosts = append(hosts, ghmcp.HostConfig{
+			Name:  name,
+			Host:  host,
+			Token: os.Getenv("GITHUB_PERSONAL_ACCESS_TOKEN_" + strings.ToUpper(name)),
+		})
+	}
+	return hosts, nil
+}
+
+// networkConfig reads the certificate authority, client certificate and proxy settings from flags
+// and env vars.
+func networkConfig() (ghmcp.NetworkConfig, error) {
+	// See the comment in stdioCmd for why we're not using viper.GetStringSlice.
+	var caBundles, certs, keys, noProxy []string
+	for key, value := range map[string]*[]string{"ca_bundle": &caBundles, "client_cert": &certs, "client_key": &keys, "no_proxy": &noProxy} {
+		if err := viper.UnmarshalKey(key, value); err != nil {
+			return ghmcp.NetworkConfig{}, fmt.Errorf("failed to unmarshal %s: %w", key, err)
+		}
+	}
+	if len(certs) != len(keys) {
+		return ghmcp.NetworkConfig{}, fmt.Errorf("got %d client certificates and %d keys, each certificate needs a key", len(certs), len(keys))
+	}
+
+	clientCerts := make([]ghmcp.ClientCertificate, 0, len(certs))
+	for i := range certs {
+		clientCerts = append(clientCerts, ghmcp.ClientCertificate{CertPath: certs[i], KeyPath: keys[i]})
+	}
+	return ghmcp.NetworkConfig{
+		CABundlePaths:      caBundles,
+		ClientCertificates: clientCerts,
+		ProxyURL:           viper.GetString("proxy"),
+		NoProxy:            noProxy,
+	}, nil
+}
+
+// budgetConfig reads the tool result size budgets from flags and env vars.
+func budgetConfig() (budget.Config, error) {
+	unit, err := budget.ParseUnit(viper.GetString("output_budget_unit"))
+	if err != nil {
+		return budget.Config{}, err
+	}
+
+	// See the comment in stdioCmd for why we're not using viper.GetStringSlice.
+	var entries []string
+	if err := viper.UnmarshalKey("tool_output_budgets", &entries); err != nil {
+		return budget.Config{}, fmt.Errorf("failed to unmarshal tool output budgets: %w", err)
+	}
+	toolMaxSizes := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		tool, size, ok := strings.Cut(entry, "=")
+		maxSize, err := strconv.Atoi(size)
+		if !ok || tool == "" || err != nil || maxSize < 0 {
+			return budget.Config{}, fmt.Errorf("invalid tool output budget %q: must be tool=size", entry)
+		}
+		toolMaxSizes[tool] = maxSize
+	}
+
+	return budget.Config{
+		MaxSize:      viper.GetInt("output_budget"),
+		ToolMaxSizes: toolMaxSizes,
+		Unit:         unit,
+	}, nil
+}
+
+// rateLimitConfig reads the rate limit retry settings from flags and env vars.
+func rateLimitConfig() ghmcp.RateLimitConfig {
+	return ghmcp.RateLimitConfig{
+		MaxRetries: viper.GetInt("rate_limit_max_retries"),
+		MaxWait:    viper.GetDuration("rate_limit_max_wait"),
+	}
+}
+
+// cacheConfig reads the REST API response cache settings from flags and env vars.
+func cacheConfig() ghmcp.CacheConfig {
+	return ghmcp.CacheConfig{
+		MaxSize: viper.GetInt64("cache_max_size") << 20,
+		Dir:     viper.GetString("cache_dir"),
+	}
+}
+
+// auditConfig reads the audit log settings from flags and env vars.
+func auditConfig() (ghmcp.AuditConfig, error) {
+	// See the comment in stdioCmd for why we're not using viper.GetStringSlice.
+	var redact []string
+	if err := viper.UnmarshalKey("audit_redact", &redact); err != nil {
+		return ghmcp.AuditConfig{}, fmt.Errorf("failed to unmarshal audit redactions: %w", err)
+	}
+
+	return ghmcp.AuditConfig{
+		LogPath:    viper.GetString("audit_log"),
+		MaxSize:    viper.GetInt64("audit_log_max_size") << 20,
+		MaxBackups: viper.GetInt("audit_log_max_backups"),
+		Redact:     redact,
+	}, nil
+}
+
+// gitHubAppConfig reads the GitHub App authentication settings from flags and env vars.
+func gitHubAppConfig() ghmcp.GitHubAppConfig {
+	return ghmcp.GitHubAppConfig{
+		AppID:          viper.GetInt64("app_id"),
+		InstallationID: viper.GetInt64("app_installation_id"),
+		PrivateKeyPath: viper.GetString("app_private_key_path"),
+	}
+}
+
+// oauthConfig reads the OAuth device flow settings from flags and env vars.
+func oauthConfig() (ghmcp.OAuthConfig, error) {
+	credentialsPath := viper.GetString("credentials_file")
+	if credentialsPath == "" {
+		defaultPath, err := ghmcp.DefaultCredentialsPath()
+		if err != nil {
+			return ghmcp.OAuthConfig{}, err
+		}
+		credentialsPath = defaultPath
+	}
 
+	return ghmcp.OAuthConfig{
+		ClientID: viper.GetString("oauth_client_id"),
+		// The secret is deliberately only read from the GITHUB_OAUTH_CLIENT_SECRET env var
+		ClientSecret:    viper.GetString("oauth_client_secret"),
+		CredentialsPath: credentialsPath,
+	}, nil
 }
 
 func main() {
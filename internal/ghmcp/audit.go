@@ -0,0 +1,40 @@
+package ghmcp
+
+import (
+	"github.com/github/github-mcp-server/pkg/audit"
+)
+
+// AuditConfig configures the audit log of write tool calls.
+type AuditConfig struct {
+	// LogPath is the file to append audit events to, the audit log is disabled if empty
+	LogPath string
+
+	// MaxSize is the size in bytes at which the audit log is rotated, zero disables rotation
+	MaxSize int64
+
+	// MaxBackups is the number of rotated audit logs to keep
+	MaxBackups int
+
+	// Redact lists the arguments whose values are left out of the audit log, as "argument" or
+	// "tool:argument" (e.g. audit.DefaultRedactions). GitHub tokens are redacted regardless.
+	Redact []string
+}
+
+// startAudit creates the auditor of a server, returning a function that closes the audit log on
+// shutdown. The auditor is nil if the audit log is disabled.
+func startAudit(cfg AuditConfig) (*audit.Auditor, func(), error) {
+	if cfg.LogPath == "" {
+		return nil, func() {}, nil
+	}
+
+	redactor, err := audit.NewRedactor(cfg.Redact)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := audit.OpenRotatingFile(cfg.LogPath, cfg.MaxSize, cfg.MaxBackups)
+	if err != nil {
+		return nil, nil, err
+	}
+	return audit.New(file, redactor), func() { _ = file.Close() }, nil
+}
@@ -0,0 +1,81 @@
+package ghmcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMCPServer_Audit(t *testing.T) {
+	userLookups := 0
+	redactor, err := audit.NewRedactor(audit.DefaultRedactions)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+
+	ghServer := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/user":
+			userLookups++
+			_, _ = w.Write([]byte(`{"login":"octocat"}`))
+		case "/repos/octo/hello-world/issues":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"number":1,"title":"Bug"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}), MCPServerConfig{
+		EnabledToolsets: []string{"context", "issues"},
+		Auditor:         audit.New(&buf, redactor),
+	})
+
+	callTool(t, ghServer, "get_me", map[string]any{})
+	createIssue := map[string]any{"owner": "octo", "repo": "hello-world", "title": "Bug", "body": "Details"}
+	callTool(t, ghServer, "create_issue", createIssue)
+	callTool(t, ghServer, "create_issue", createIssue)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2, "only write tools should be audited")
+
+	var event audit.Event
+	require.NoError(t, json.Unmarshal(lines[0], &event))
+	assert.Equal(t, "create_issue", event.Tool)
+	assert.Equal(t, "octocat", event.Login)
+	assert.Equal(t, "octo", event.Owner)
+	assert.Equal(t, "hello-world", event.Repo)
+	assert.Equal(t, audit.OutcomeSuccess, event.Outcome)
+	assert.Equal(t, audit.Redacted, event.Arguments["body"])
+
+	// get_me looked up the user once, and the audit log once, after which the login is remembered
+	assert.Equal(t, 2, userLookups)
+	assert.NotContains(t, string(lines[0]), `"dry_run"`)
+}
+
+func TestNewMCPServer_AuditDryRun(t *testing.T) {
+	redactor, err := audit.NewRedactor(audit.DefaultRedactions)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+
+	ghServer := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "dry-run writes should not reach GitHub")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login":"octocat"}`))
+	}), MCPServerConfig{
+		EnabledToolsets: []string{"issues"},
+		Auditor:         audit.New(&buf, redactor),
+		DryRun:          true,
+	})
+
+	callTool(t, ghServer, "create_issue", map[string]any{"owner": "octo", "repo": "hello-world", "title": "Bug"})
+
+	var event audit.Event
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &event))
+	assert.Equal(t, "create_issue", event.Tool)
+	assert.Equal(t, audit.OutcomeSuccess, event.Outcome)
+	assert.True(t, event.DryRun)
+}
@@ -0,0 +1,50 @@
+package ghmcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/budget"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMCPServer_Budget(t *testing.T) {
+	requests := 0
+	ghServer := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login":"octocat","id":1,"html_url":"https://github.com/octocat","avatar_url":"https://avatars.githubusercontent.com/u/1"}`))
+	}), MCPServerConfig{
+		EnabledToolsets: []string{"context"},
+		Budget:          budget.Config{ToolMaxSizes: map[string]int{"get_me": 40}},
+	})
+
+	tools := listTools(t, ghServer)
+	require.Len(t, tools, 1)
+	assert.Contains(t, tools["get_me"].InputSchema.Properties, budget.ContinuationArgument)
+
+	var text strings.Builder
+	arguments := map[string]any{}
+	for {
+		result := callTool(t, ghServer, "get_me", arguments)
+		require.False(t, result.IsError, result.Content)
+		require.Len(t, result.Content, 2)
+		text.WriteString(result.Content[0].(mcp.TextContent).Text)
+
+		note := result.Content[1].(mcp.TextContent).Text
+		_, token, ok := strings.Cut(note, budget.ContinuationArgument+` "`)
+		if !ok {
+			break
+		}
+		arguments = map[string]any{budget.ContinuationArgument: strings.TrimSuffix(token, `".`)}
+	}
+
+	var user map[string]any
+	require.NoError(t, json.Unmarshal([]byte(text.String()), &user), "the parts make up the whole result")
+	assert.Equal(t, "octocat", user["login"])
+	assert.Equal(t, 1, requests, "continuation calls do not query GitHub")
+}
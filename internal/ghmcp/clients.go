@@ -0,0 +1,249 @@
+package ghmcp
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gogithub "github.com/google/go-github/v73/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// githubClients holds the API clients that act on behalf of a single token.
+type githubClients struct {
+	rest    *gogithub.Client
+	gql     *githubv4.Client
+	gqlHTTP *http.Client
+	// gqlAuth is the authenticated transport underneath the GraphQL user agent transport
+	gqlAuth http.RoundTripper
+
+	// login is the login of the authenticated user, looked up on first use by authenticatedLogin
+	loginMu    sync.Mutex
+	login      string
+	loginKnown bool
+}
+
+// clientTransports are the transports shared by the clients of all tokens, underneath authentication.
+type clientTransports struct {
+	rest    http.RoundTripper
+	graphql http.RoundTripper
+}
+
+func newGitHubClients(host apiHost, transports clientTransports, tokens tokenSource, userAgent string) *githubClients {
+	// Construct our REST client
+	restClient := gogithub.NewClient(&http.Client{
+		Transport: &bearerAuthTransport{
+			transport: transports.rest,
+			tokens:    tokens,
+		},
+	})
+	restClient.UserAgent = userAgent
+	restClient.BaseURL = host.baseRESTURL
+	restClient.UploadURL = host.uploadURL
+
+	// Construct our GraphQL client
+	// We're using NewEnterpriseClient here unconditionally as opposed to NewClient because we already
+	// did the necessary API host parsing so that github.com will return the correct URL anyway.
+	gqlAuth := &bearerAuthTransport{
+		transport: transports.graphql,
+		tokens:    tokens,
+	}
+	gqlHTTPClient := &http.Client{
+		Transport: &userAgentTransport{
+			transport: gqlAuth,
+			agent:     userAgent,
+		},
+	}
+	gqlClient := githubv4.NewEnterpriseClient(host.graphqlURL.String(), gqlHTTPClient)
+
+	return &githubClients{
+		rest:    restClient,
+		gql:     gqlClient,
+		gqlHTTP: gqlHTTPClient,
+		gqlAuth: gqlAuth,
+	}
+}
+
+func (c *githubClients) setUserAgent(agent string) {
+	c.rest.UserAgent = agent
+	c.gqlHTTP.Transport = &userAgentTransport{
+		transport: c.gqlAuth,
+		agent:     agent,
+	}
+}
+
+// authenticatedLogin returns the login of the user the clients act as, looking it up the first
+// time. Tokens that cannot look up a user, such as GitHub App installation tokens, have no login.
+func (c *githubClients) authenticatedLogin(ctx context.Context) string {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if c.loginKnown {
+		return c.login
+	}
+
+	user, _, err := c.rest.Users.Get(ctx, "")
+	if err != nil {
+		// Only remember failures that will not go away by retrying
+		var errResp *gogithub.ErrorResponse
+		c.loginKnown = errors.As(err, &errResp)
+		return ""
+	}
+	c.login, c.loginKnown = user.GetLogin(), true
+	return c.login
+}
+
+// loginFor returns a function that looks up the login a tool call is made as, which is empty if unknown.
+func loginFor(clientsFor func(ctx context.Context) (*githubClients, error)) func(ctx context.Context) string {
+	return func(ctx context.Context) string {
+		clients, err := clientsFor(ctx)
+		if err != nil {
+			return ""
+		}
+		return clients.authenticatedLogin(ctx)
+	}
+}
+
+// serverCredentialsKey is the cache key of the clients using the server's own credentials,
+// as opposed to a token supplied with the request.
+const serverCredentialsKey = ""
+
+const (
+	// maxCachedClients is the number of tokens clients are cached for, the least recently used
+	// being dropped first
+	maxCachedClients = 1000
+	// cachedClientsTTL is how long the clients of a token are kept after their last use
+	cachedClientsTTL = 30 * time.Minute
+)
+
+// clientCache lazily builds and caches GitHub clients per token, so that a single
+// server can act on behalf of several users. Clients are cached under a hash of the token,
+// and dropped when they have not been used for cachedClientsTTL or when more than
+// maxCachedClients tokens have been seen.
+type clientCache struct {
+	host       apiHost
+	transports clientTransports
+	now        func() time.Time
+
+	mu        sync.Mutex
+	userAgent string
+	// order holds the cached clients from the most to the least recently used
+	order   *list.List
+	clients map[string]*list.Element
+}
+
+type cachedClients struct {
+	key      string
+	clients  *githubClients
+	lastUsed time.Time
+}
+
+func newClientCache(host apiHost, transports clientTransports, userAgent string) *clientCache {
+	return &clientCache{
+		host:       host,
+		transports: transports,
+		now:        time.Now,
+		userAgent:  userAgent,
+		order:      list.New(),
+		clients:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the clients cached under key, creating them with tokens if the key is not cached.
+func (c *clientCache) get(key string, tokens tokenSource) *githubClients {
+	sum := sha256.Sum256([]byte(key))
+	key = hex.EncodeToString(sum[:])
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Expired clients are at the back, as they were used least recently
+	for element := c.order.Back(); element != nil; element = c.order.Back() {
+		entry := element.Value.(*cachedClients)
+		if now.Sub(entry.lastUsed) < cachedClientsTTL {
+			break
+		}
+		c.remove(element)
+	}
+
+	if element, ok := c.clients[key]; ok {
+		entry := element.Value.(*cachedClients)
+		entry.lastUsed = now
+		c.order.MoveToFront(element)
+		return entry.clients
+	}
+
+	entry := &cachedClients{
+		key:      key,
+		clients:  newGitHubClients(c.host, c.transports, tokens, c.userAgent),
+		lastUsed: now,
+	}
+	c.clients[key] = c.order.PushFront(entry)
+	if c.order.Len() > maxCachedClients {
+		c.remove(c.order.Back())
+	}
+	return entry.clients
+}
+
+func (c *clientCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.clients, element.Value.(*cachedClients).key)
+}
+
+// anonymousREST returns a REST client that does not authenticate, for the few endpoints that
+// need no credentials.
+func (c *clientCache) anonymousREST() *gogithub.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	client := gogithub.NewClient(&http.Client{Transport: c.transports.rest})
+	client.UserAgent = c.userAgent
+	client.BaseURL = c.host.baseRESTURL
+	client.UploadURL = c.host.uploadURL
+	return client
+}
+
+// setUserAgent updates the user agent of all cached clients and of any clients created afterwards.
+func (c *clientCache) setUserAgent(agent string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.userAgent = agent
+	for element := c.order.Front(); element != nil; element = element.Next() {
+		element.Value.(*cachedClients).clients.setUserAgent(agent)
+	}
+}
+
+type tokenCtxKey struct{}
+
+// contextWithToken returns a context carrying the GitHub token to use for the request.
+func contextWithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenCtxKey{}, token)
+}
+
+// tokenFromContext returns the GitHub token carried by the request context, if any.
+func tokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(tokenCtxKey{}).(string)
+	return token, ok && token != ""
+}
+
+// parseAuthorizationHeader extracts the token from an "Authorization: Bearer <token>"
+// or "Authorization: token <token>" header value.
+func parseAuthorizationHeader(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found {
+		return "", false
+	}
+	if !strings.EqualFold(scheme, "bearer") && !strings.EqualFold(scheme, "token") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,119 @@
+package ghmcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuthorizationHeader(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        string
+		expectedToken string
+		expectedOK    bool
+	}{
+		{name: "bearer scheme", header: "Bearer ghp_abc", expectedToken: "ghp_abc", expectedOK: true},
+		{name: "token scheme", header: "token ghp_abc", expectedToken: "ghp_abc", expectedOK: true},
+		{name: "scheme is case insensitive", header: "BEARER ghp_abc", expectedToken: "ghp_abc", expectedOK: true},
+		{name: "empty header", header: "", expectedOK: false},
+		{name: "missing token", header: "Bearer ", expectedOK: false},
+		{name: "unsupported scheme", header: "Basic dXNlcjpwYXNz", expectedOK: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			token, ok := parseAuthorizationHeader(tc.header)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedToken, token)
+		})
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	host, err := newDotcomHost()
+	require.NoError(t, err)
+
+	cache := newClientCache(host, clientTransports{rest: http.DefaultTransport, graphql: http.DefaultTransport}, "github-mcp-server/test")
+
+	first := cache.get("token-a", staticTokenSource("token-a"))
+	assert.Same(t, first, cache.get("token-a", staticTokenSource("token-a")), "clients should be reused for the same token")
+	assert.NotSame(t, first, cache.get("token-b", staticTokenSource("token-b")), "clients should not be shared between tokens")
+	assert.Equal(t, "github-mcp-server/test", first.rest.UserAgent)
+
+	cache.setUserAgent("github-mcp-server/test (client/1.0)")
+	assert.Equal(t, "github-mcp-server/test (client/1.0)", first.rest.UserAgent)
+	assert.Equal(t, "github-mcp-server/test (client/1.0)", cache.get("token-c", staticTokenSource("token-c")).rest.UserAgent)
+	assert.NotContains(t, cache.clients, "token-a", "tokens should not be kept as keys")
+}
+
+func TestClientCacheEviction(t *testing.T) {
+	host, err := newDotcomHost()
+	require.NoError(t, err)
+
+	cache := newClientCache(host, clientTransports{rest: http.DefaultTransport, graphql: http.DefaultTransport}, "github-mcp-server/test")
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	first := cache.get("token-0", staticTokenSource("token-0"))
+	for i := 1; i <= maxCachedClients; i++ {
+		token := fmt.Sprintf("token-%d", i)
+		cache.get(token, staticTokenSource(token))
+	}
+	assert.Len(t, cache.clients, maxCachedClients)
+	assert.NotSame(t, first, cache.get("token-0", staticTokenSource("token-0")), "the least recently used clients should be dropped")
+
+	recent := cache.get("token-1000", staticTokenSource("token-1000"))
+	now = now.Add(cachedClientsTTL - time.Second)
+	assert.Same(t, recent, cache.get("token-1000", staticTokenSource("token-1000")))
+	now = now.Add(cachedClientsTTL)
+	assert.NotSame(t, recent, cache.get("token-1000", staticTokenSource("token-1000")), "clients unused for the TTL should be dropped")
+	assert.Len(t, cache.clients, 1)
+}
+
+func TestTokenFromContext(t *testing.T) {
+	_, ok := tokenFromContext(context.Background())
+	assert.False(t, ok)
+
+	token, ok := tokenFromContext(contextWithToken(context.Background(), "ghp_abc"))
+	assert.True(t, ok)
+	assert.Equal(t, "ghp_abc", token)
+}
+
+func TestRequireAuthorization(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		required       bool
+		header         string
+		expectedStatus int
+	}{
+		{name: "required and present", required: true, header: "Bearer ghp_abc", expectedStatus: http.StatusOK},
+		{name: "required and missing", required: true, expectedStatus: http.StatusUnauthorized},
+		{name: "required and malformed", required: true, header: "ghp_abc", expectedStatus: http.StatusUnauthorized},
+		{name: "not required and missing", required: false, expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+
+			requireAuthorization(next, tc.required).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+}
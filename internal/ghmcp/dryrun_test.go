@@ -0,0 +1,62 @@
+package ghmcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/dryrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMCPServer_DryRun(t *testing.T) {
+	ghServer := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s %s in dry-run mode", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/octo/hello-world/git/ref/heads/main":
+			_, _ = w.Write([]byte(`{"ref":"refs/heads/main","object":{"sha":"abc123"}}`))
+		case "/repos/octo/hello-world/git/commits/abc123":
+			_, _ = w.Write([]byte(`{"sha":"abc123","tree":{"sha":"def456"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}), MCPServerConfig{
+		EnabledToolsets: []string{"repos"},
+		DryRun:          true,
+	})
+
+	result := callTool(t, ghServer, "push_files", map[string]any{
+		"owner":   "octo",
+		"repo":    "hello-world",
+		"branch":  "main",
+		"message": "Update README",
+		"files":   []any{map[string]any{"path": "README.md", "content": "# Hello"}},
+	})
+	require.False(t, result.IsError, result.Content)
+
+	var report dryrun.Report
+	require.NoError(t, json.Unmarshal([]byte(toolText(t, result)), &report))
+	assert.True(t, report.DryRun)
+	require.Len(t, report.Mutations, 3)
+	assert.Equal(t, "POST /repos/{owner}/{repo}/git/trees", report.Mutations[0].Method+" "+report.Mutations[0].Endpoint)
+	assert.Equal(t, "POST /repos/{owner}/{repo}/git/commits", report.Mutations[1].Method+" "+report.Mutations[1].Endpoint)
+	assert.Equal(t, "PATCH /repos/{owner}/{repo}/git/refs/{ref}", report.Mutations[2].Method+" "+report.Mutations[2].Endpoint)
+	assert.Equal(t, "def456", report.Mutations[0].Body.(map[string]any)["base_tree"])
+
+	// Refs are still resolved with real reads
+	result = callTool(t, ghServer, "push_files", map[string]any{
+		"owner":   "octo",
+		"repo":    "hello-world",
+		"branch":  "missing",
+		"message": "Update README",
+		"files":   []any{map[string]any{"path": "README.md", "content": "# Hello"}},
+	})
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,181 @@
+package ghmcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	gogithub "github.com/google/go-github/v73/github"
+)
+
+// GitHubAppConfig configures authentication as an installation of a GitHub App.
+type GitHubAppConfig struct {
+	// AppID is the ID of the GitHub App
+	AppID int64
+
+	// InstallationID is the ID of the installation to mint installation tokens for
+	InstallationID int64
+
+	// PrivateKeyPath is the path to the PEM encoded private key of the GitHub App
+	PrivateKeyPath string
+}
+
+// Enabled reports whether GitHub App authentication has been configured.
+func (c GitHubAppConfig) Enabled() bool {
+	return c.AppID != 0
+}
+
+// Validate checks that either none or all of the GitHub App settings are present.
+func (c GitHubAppConfig) Validate() error {
+	if !c.Enabled() && c.InstallationID == 0 && c.PrivateKeyPath == "" {
+		return nil
+	}
+	if c.AppID == 0 || c.InstallationID == 0 || c.PrivateKeyPath == "" {
+		return fmt.Errorf("GitHub App authentication requires an app ID, installation ID and private key path")
+	}
+	return nil
+}
+
+// tokenSource provides the token used to authenticate a request against the GitHub API.
+type tokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// staticTokenSource always provides the same token, e.g. a personal access token.
+type staticTokenSource string
+
+func (s staticTokenSource) Token(_ context.Context) (string, error) {
+	return string(s), nil
+}
+
+const (
+	// appJWTLifetime is the lifetime of the JWTs used to authenticate as the app, GitHub allows at most 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew backdates the JWT issue time to allow for clock drift between us and GitHub.
+	appJWTClockSkew = 60 * time.Second
+	// tokenRefreshMargin is how long before expiry an installation or OAuth token is replaced.
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+// appJWTSource signs a short-lived JWT identifying the GitHub App itself.
+type appJWTSource struct {
+	appID int64
+	key   *rsa.PrivateKey
+	now   func() time.Time
+}
+
+func (s *appJWTSource) Token(_ context.Context) (string, error) {
+	now := s.now()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT claims: %w", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// installationTokenSource mints installation tokens for a GitHub App and
+// refreshes them shortly before they expire.
+type installationTokenSource struct {
+	installationID int64
+	client         *gogithub.Client
+	now            func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func newInstallationTokenSource(cfg GitHubAppConfig, host apiHost, transport http.RoundTripper) (*installationTokenSource, error) {
+	pemBytes, err := os.ReadFile(cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	key, err := parseRSAPrivateKey(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// The app itself authenticates with a JWT, which is only good for minting installation tokens
+	client := gogithub.NewClient(&http.Client{
+		Transport: &bearerAuthTransport{
+			transport: transport,
+			tokens:    &appJWTSource{appID: cfg.AppID, key: key, now: time.Now},
+		},
+	})
+	client.BaseURL = host.baseRESTURL
+
+	return &installationTokenSource{
+		installationID: cfg.InstallationID,
+		client:         client,
+		now:            time.Now,
+	}, nil
+}
+
+func (s *installationTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Add(tokenRefreshMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	token, _, err := s.client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create installation token: %w", err)
+	}
+
+	s.token = token.GetToken()
+	s.expiresAt = token.GetExpiresAt().Time
+	return s.token, nil
+}
+
+// parseRSAPrivateKey parses a PEM encoded PKCS#1 or PKCS#8 RSA private key.
+func parseRSAPrivateKey(pemBytes []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key must be an RSA key, is %T", parsed)
+	}
+	return key, nil
+}
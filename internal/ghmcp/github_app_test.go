@@ -0,0 +1,121 @@
+package ghmcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubAppConfig_Validate(t *testing.T) {
+	assert.NoError(t, GitHubAppConfig{}.Validate())
+	assert.NoError(t, GitHubAppConfig{AppID: 1, InstallationID: 2, PrivateKeyPath: "key.pem"}.Validate())
+	assert.Error(t, GitHubAppConfig{AppID: 1}.Validate())
+	assert.Error(t, GitHubAppConfig{InstallationID: 2, PrivateKeyPath: "key.pem"}.Validate())
+}
+
+func TestInstallationTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}), 0600))
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	minted := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/app/installations/42/access_tokens", r.URL.Path)
+		verifyAppJWT(t, &key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), "7")
+
+		minted++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("ghs_%d", minted),
+			"expires_at": now.Add(time.Hour).Format(time.RFC3339),
+		})
+	}))
+	defer ts.Close()
+
+	restURL, err := url.Parse(ts.URL + "/")
+	require.NoError(t, err)
+
+	source, err := newInstallationTokenSource(GitHubAppConfig{
+		AppID:          7,
+		InstallationID: 42,
+		PrivateKeyPath: keyPath,
+	}, apiHost{baseRESTURL: restURL}, http.DefaultTransport)
+	require.NoError(t, err)
+	source.now = func() time.Time { return now }
+
+	token, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_1", token)
+
+	// The token is reused while it is still valid
+	now = now.Add(30 * time.Minute)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_1", token)
+
+	// ...and replaced once it is about to expire
+	now = now.Add(26 * time.Minute)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_2", token)
+}
+
+func TestParseRSAPrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	parsed, err := parseRSAPrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	_, err = parseRSAPrivateKey([]byte("not a key"))
+	assert.Error(t, err)
+}
+
+// verifyAppJWT checks that jwt is signed by the app key and issued by appID.
+func verifyAppJWT(t *testing.T, key *rsa.PublicKey, jwt string, appID string) {
+	t.Helper()
+
+	parts := strings.Split(jwt, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature))
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims map[string]any
+	require.NoError(t, json.Unmarshal(claimsJSON, &claims))
+	assert.Equal(t, appID, claims["iss"])
+}
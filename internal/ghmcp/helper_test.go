@@ -0,0 +1,150 @@
+package ghmcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer returns an MCP server created from cfg as testServerConfig completes it.
+func newTestServer(t *testing.T, handler http.Handler, cfg MCPServerConfig) *server.MCPServer {
+	t.Helper()
+	ghServer, err := NewMCPServer(testServerConfig(t, handler, cfg))
+	require.NoError(t, err)
+	return ghServer
+}
+
+// testServerConfig fills in the version, token and translator of cfg when they are not set. If
+// handler is not nil, it answers the REST, GraphQL, upload and raw requests of the default host.
+func testServerConfig(t *testing.T, handler http.Handler, cfg MCPServerConfig) MCPServerConfig {
+	t.Helper()
+	if handler != nil {
+		ts := httptest.NewServer(handler)
+		t.Cleanup(ts.Close)
+		cfg.HostOverrides = APIHostOverrides{
+			RESTURL:    ts.URL,
+			GraphQLURL: ts.URL + "/graphql",
+			UploadURL:  ts.URL + "/uploads/",
+			RawURL:     ts.URL + "/raw/",
+		}
+	}
+	if cfg.Version == "" {
+		cfg.Version = "test"
+	}
+	if cfg.Token == "" && !cfg.GitHubApp.Enabled() {
+		cfg.Token = "ghp_test"
+	}
+	if cfg.Translator == nil {
+		cfg.Translator = translations.NullTranslationHelper
+	}
+	return cfg
+}
+
+// sendRequest sends a JSON-RPC request to the server in ctx and returns its response.
+func sendRequest(ctx context.Context, t *testing.T, s *server.MCPServer, method string, params map[string]any) mcp.JSONRPCMessage {
+	t.Helper()
+	request, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	require.NoError(t, err)
+	return s.HandleMessage(ctx, request)
+}
+
+// sendResult sends a JSON-RPC request to the server and returns the result of its response.
+func sendResult(t *testing.T, s *server.MCPServer, method string, params map[string]any) any {
+	t.Helper()
+	response, ok := sendRequest(context.Background(), t, s, method, params).(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected a result")
+	return response.Result
+}
+
+// callTool calls the tool with the arguments and returns its result.
+func callTool(t *testing.T, s *server.MCPServer, name string, arguments map[string]any) mcp.CallToolResult {
+	t.Helper()
+	return callToolWithContext(context.Background(), t, s, name, arguments)
+}
+
+// callToolWithContext calls the tool in ctx, which may carry the request token, and returns its result.
+func callToolWithContext(ctx context.Context, t *testing.T, s *server.MCPServer, name string, arguments map[string]any) mcp.CallToolResult {
+	t.Helper()
+	response, ok := sendRequest(ctx, t, s, "tools/call", map[string]any{"name": name, "arguments": arguments}).(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected a result")
+	result, ok := response.Result.(mcp.CallToolResult)
+	require.True(t, ok)
+	return result
+}
+
+// listTools returns the descriptions of the tools the server lists, by name.
+func listTools(t *testing.T, s *server.MCPServer) map[string]mcp.Tool {
+	t.Helper()
+	result, ok := sendResult(t, s, "tools/list", map[string]any{}).(mcp.ListToolsResult)
+	require.True(t, ok)
+	tools := make(map[string]mcp.Tool, len(result.Tools))
+	for _, tool := range result.Tools {
+		tools[tool.Name] = tool
+	}
+	return tools
+}
+
+// postMCP sends a JSON-RPC request to the streamable HTTP endpoint at url in the session, if not
+// empty, and returns the response and the body of its JSON-RPC response, which may come as an
+// event stream.
+func postMCP(t *testing.T, url string, header http.Header, sessionID, method string, params map[string]any) (*http.Response, json.RawMessage) {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	// Responses upgraded to an event stream for notifications come with 202 Accepted
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return resp, nil
+	}
+
+	var message json.RawMessage
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		// Notifications come first in the stream, the response to the request last
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				message = json.RawMessage(data)
+			}
+		}
+		require.NoError(t, scanner.Err())
+	} else {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&message))
+	}
+	return resp, message
+}
+
+// toolText returns the text of the first content of a tool result.
+func toolText(t *testing.T, result mcp.CallToolResult) string {
+	t.Helper()
+	require.NotEmpty(t, result.Content)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
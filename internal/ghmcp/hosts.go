@@ -0,0 +1,192 @@
+package ghmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultHostName is the name of the host set with Host, which tools use when no host is given.
+const DefaultHostName = "default"
+
+// hostArgument is the tool argument that selects the host a tool call is made against.
+const hostArgument = "host"
+
+// HostTokenHeaderPrefix is followed by the name of an additional host to form the HTTP header
+// in which callers send their own token for that host, such as X-GitHub-Token-ghes.
+const HostTokenHeaderPrefix = "X-GitHub-Token-"
+
+// hostNamePattern limits host names to what can be part of an env var name.
+var hostNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// HostConfig configures an additional GitHub host served alongside the default one.
+type HostConfig struct {
+	// Name selects the host with the host argument of tools
+	Name string
+
+	// Host to target for API requests, like MCPServerConfig.Host
+	Host string
+
+	// Token to authenticate with the API of the host
+	Token string
+}
+
+// validateHosts checks that the additional hosts have distinct, usable names and a token.
+func validateHosts(hosts []HostConfig) error {
+	seen := map[string]bool{DefaultHostName: true}
+	for _, host := range hosts {
+		if !hostNamePattern.MatchString(host.Name) {
+			return fmt.Errorf("invalid host name %q: must start with a letter and contain only lowercase letters, digits and underscores", host.Name)
+		}
+		if seen[host.Name] {
+			return fmt.Errorf("host %q is configured more than once", host.Name)
+		}
+		seen[host.Name] = true
+		if host.Token == "" {
+			return fmt.Errorf("no GitHub token provided for host %q", host.Name)
+		}
+	}
+	return nil
+}
+
+type hostCtxKey struct{}
+
+type hostTokensCtxKey struct{}
+
+// contextWithHostTokens returns a context carrying the caller's tokens for additional hosts, by host name.
+func contextWithHostTokens(ctx context.Context, tokens map[string]string) context.Context {
+	return context.WithValue(ctx, hostTokensCtxKey{}, tokens)
+}
+
+// hostTokenFromContext returns the caller's token for the named host carried by the request context, if any.
+func hostTokenFromContext(ctx context.Context, name string) (string, bool) {
+	tokens, _ := ctx.Value(hostTokensCtxKey{}).(map[string]string)
+	token, ok := tokens[name]
+	return token, ok && token != ""
+}
+
+// hostTokensFromHeader returns the tokens for the hosts sent in their HostTokenHeaderPrefix
+// headers, by host name.
+func hostTokensFromHeader(header http.Header, hosts []HostConfig) map[string]string {
+	tokens := make(map[string]string)
+	for _, host := range hosts {
+		if token := strings.TrimSpace(header.Get(HostTokenHeaderPrefix + host.Name)); token != "" {
+			tokens[host.Name] = token
+		}
+	}
+	return tokens
+}
+
+// contextWithHost returns a context carrying the name of the host to make requests against.
+func contextWithHost(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, hostCtxKey{}, name)
+}
+
+// hostFromContext returns the name of the host to make requests against, which is the default
+// host unless another one was selected.
+func hostFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(hostCtxKey{}).(string); ok {
+		return name
+	}
+	return DefaultHostName
+}
+
+// hostToolMiddleware routes tool calls to the host named by their host argument, or the default
+// host without one, unless supported returns an error for the call on that host. get_me reports
+// the user on every host when called without one.
+func hostToolMiddleware(names []string, supported func(request mcp.CallToolRequest, host string) error) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name := DefaultHostName
+			if arg, ok := request.GetArguments()[hostArgument]; ok {
+				if name, ok = arg.(string); !ok {
+					return mcp.NewToolResultError(fmt.Sprintf("parameter %s is not of type string, is %T", hostArgument, arg)), nil
+				}
+				if !slices.Contains(names, name) {
+					return mcp.NewToolResultError(fmt.Sprintf("unknown host %q", name)), nil
+				}
+			} else if request.Params.Name == "get_me" {
+				return identities(ctx, names, next, request)
+			}
+			if err := supported(request, name); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("%s on host %s", err, name)), nil
+			}
+			return next(contextWithHost(ctx, name), request)
+		}
+	}
+}
+
+// identities calls get_me against each of the hosts and combines the results by host name.
+func identities(ctx context.Context, names []string, next server.ToolHandlerFunc, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	users := make(map[string]any, len(names))
+	for _, name := range names {
+		result, err := next(contextWithHost(ctx, name), request)
+		switch {
+		case err != nil:
+			users[name] = map[string]string{"error": err.Error()}
+		case result.IsError:
+			users[name] = map[string]string{"error": resultText(result)}
+		default:
+			text := resultText(result)
+			if json.Valid([]byte(text)) {
+				users[name] = json.RawMessage(text)
+			} else {
+				users[name] = text
+			}
+		}
+	}
+
+	r, err := json.Marshal(users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal users: %w", err)
+	}
+	return mcp.NewToolResultText(string(r)), nil
+}
+
+// resultText returns the text content of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	var text string
+	for _, content := range result.Content {
+		if c, ok := content.(mcp.TextContent); ok {
+			text += c.Text
+		}
+	}
+	return text
+}
+
+// hostToolFilter lists the tools that at least one of the hosts supports, with a host argument
+// naming the hosts that do.
+func hostToolFilter(names []string, supported func(tool, host string) error) server.ToolFilterFunc {
+	return func(_ context.Context, tools []mcp.Tool) []mcp.Tool {
+		filtered := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			hosts := make([]string, 0, len(names))
+			for _, name := range names {
+				if supported(tool.Name, name) == nil {
+					hosts = append(hosts, name)
+				}
+			}
+			if len(hosts) == 0 {
+				continue
+			}
+			description := fmt.Sprintf("Name of the GitHub host to use, defaults to %q", DefaultHostName)
+			if hosts[0] != DefaultHostName {
+				description = "Name of the GitHub host to use, the default host does not have this tool"
+			}
+			filtered = append(filtered, github.WithToolProperty(tool, hostArgument, map[string]any{
+				"type":        "string",
+				"description": description,
+				"enum":        hosts,
+			}))
+		}
+		return filtered
+	}
+}
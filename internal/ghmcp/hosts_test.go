@@ -0,0 +1,117 @@
+package ghmcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userHandler answers the authenticated user lookup with a login depending on the token.
+func userHandler(logins map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		login, ok := logins[r.Header.Get("Authorization")]
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login":"` + login + `","id":1}`))
+	})
+}
+
+func userServer(logins map[string]string) *httptest.Server {
+	return httptest.NewServer(userHandler(logins))
+}
+
+func TestNewMCPServer_Hosts(t *testing.T) {
+	ghes := userServer(map[string]string{"Bearer ghp_ghes": "hubot", "Bearer ghp_caller_ghes": "monalisa"})
+	defer ghes.Close()
+
+	ghServer := newTestServer(t, userHandler(map[string]string{"Bearer ghp_dotcom": "octocat"}), MCPServerConfig{
+		Token:           "ghp_dotcom",
+		EnabledToolsets: []string{"context"},
+		Hosts:           []HostConfig{{Name: "ghes", Host: ghes.URL, Token: "ghp_ghes"}},
+	})
+
+	// Tools take the host as an argument
+	tools := listTools(t, ghServer)
+	require.NotEmpty(t, tools)
+	for _, tool := range tools {
+		assert.Equal(t, []string{"default", "ghes"}, tool.InputSchema.Properties["host"].(map[string]any)["enum"], tool.Name)
+	}
+
+	result := callTool(t, ghServer, "get_me", map[string]any{"host": "ghes"})
+	require.False(t, result.IsError, result.Content)
+	assert.Contains(t, toolText(t, result), `"login":"hubot"`)
+
+	result = callTool(t, ghServer, "get_me", map[string]any{"host": "ghae"})
+	assert.True(t, result.IsError)
+	assert.Equal(t, `unknown host "ghae"`, toolText(t, result))
+
+	// Without a host, get_me reports the user on every host
+	result = callTool(t, ghServer, "get_me", map[string]any{})
+	require.False(t, result.IsError, result.Content)
+	var users map[string]struct {
+		Login string `json:"login"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(toolText(t, result)), &users))
+	assert.Equal(t, "octocat", users["default"].Login)
+	assert.Equal(t, "hubot", users["ghes"].Login)
+
+	// Callers with a token of their own never act as the server on additional hosts
+	ctx := contextWithToken(context.Background(), "ghp_caller")
+	result = callToolWithContext(ctx, t, ghServer, "get_me", map[string]any{"host": "ghes"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, toolText(t, result), "no GitHub token provided for host ghes, send one in the X-GitHub-Token-ghes header")
+
+	ctx = contextWithHostTokens(ctx, hostTokensFromHeader(http.Header{"X-Github-Token-Ghes": {"ghp_caller_ghes"}}, []HostConfig{{Name: "ghes"}}))
+	result = callToolWithContext(ctx, t, ghServer, "get_me", map[string]any{"host": "ghes"})
+	require.False(t, result.IsError, result.Content)
+	assert.Contains(t, toolText(t, result), `"login":"monalisa"`)
+}
+
+func Test_ValidateHosts(t *testing.T) {
+	tests := []struct {
+		name           string
+		hosts          []HostConfig
+		expectedErrMsg string
+	}{
+		{
+			name:  "valid",
+			hosts: []HostConfig{{Name: "ghes", Host: "https://github.example.com", Token: "ghp_test"}},
+		},
+		{
+			name:           "default name",
+			hosts:          []HostConfig{{Name: "default", Host: "https://github.example.com", Token: "ghp_test"}},
+			expectedErrMsg: `host "default" is configured more than once`,
+		},
+		{
+			name:           "invalid name",
+			hosts:          []HostConfig{{Name: "GHES-1", Host: "https://github.example.com", Token: "ghp_test"}},
+			expectedErrMsg: `invalid host name "GHES-1"`,
+		},
+		{
+			name:           "no token",
+			hosts:          []HostConfig{{Name: "ghes", Host: "https://github.example.com"}},
+			expectedErrMsg: `no GitHub token provided for host "ghes"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateHosts(tc.hosts)
+			if tc.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
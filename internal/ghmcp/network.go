@@ -0,0 +1,94 @@
+package ghmcp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// NetworkConfig configures the connections to GitHub, for networks with a proxy, an internal
+// certificate authority or servers that require client certificates.
+type NetworkConfig struct {
+	// CABundlePaths are PEM files of certificate authorities to trust in addition to the system ones
+	CABundlePaths []string
+
+	// ClientCertificates are presented to servers that request a client certificate
+	ClientCertificates []ClientCertificate
+
+	// ProxyURL, if set, is the proxy for all requests instead of the HTTPS_PROXY and HTTP_PROXY env vars
+	ProxyURL string
+
+	// NoProxy lists hosts, domains, IP addresses and CIDR ranges that are connected to directly
+	// instead of those in the NO_PROXY env var
+	NoProxy []string
+}
+
+// ClientCertificate is a PEM certificate and private key pair for TLS client authentication.
+type ClientCertificate struct {
+	CertPath string
+	KeyPath  string
+}
+
+// transport returns the transport underneath all requests to GitHub. Without settings, it is
+// http.DefaultTransport, which uses the system certificate authorities and the proxy env vars.
+func (c NetworkConfig) transport() (http.RoundTripper, error) {
+	if len(c.CABundlePaths) == 0 && len(c.ClientCertificates) == 0 && c.ProxyURL == "" && len(c.NoProxy) == 0 {
+		return http.DefaultTransport, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(c.CABundlePaths) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load system certificate authorities: %w", err)
+		}
+		for _, path := range c.CABundlePaths {
+			pemBytes, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pemBytes) {
+				return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", path)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	for _, pair := range c.ClientCertificates {
+		cert, err := tls.LoadX509KeyPair(pair.CertPath, pair.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate %s: %w", pair.CertPath, err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if c.ProxyURL != "" || len(c.NoProxy) > 0 {
+		// Settings that are not given keep coming from the env vars
+		proxyConfig := httpproxy.FromEnvironment()
+		if c.ProxyURL != "" {
+			if _, err := url.Parse(c.ProxyURL); err != nil {
+				return nil, fmt.Errorf("invalid proxy URL: %w", err)
+			}
+			proxyConfig.HTTPProxy = c.ProxyURL
+			proxyConfig.HTTPSProxy = c.ProxyURL
+		}
+		if len(c.NoProxy) > 0 {
+			proxyConfig.NoProxy = strings.Join(c.NoProxy, ",")
+		}
+		proxy := proxyConfig.ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}
+	}
+
+	return transport, nil
+}
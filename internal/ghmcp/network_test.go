@@ -0,0 +1,132 @@
+package ghmcp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM writes a PEM block of the type to a file in dir and returns its path.
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+	return path
+}
+
+func Test_NetworkConfigTransport(t *testing.T) {
+	dir := t.TempDir()
+
+	// A client certificate, which the server below requires
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "github-mcp-server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	clientCert := ClientCertificate{
+		CertPath: writePEM(t, dir, "client.pem", "CERTIFICATE", certDER),
+		KeyPath:  writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER),
+	}
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MinVersion: tls.VersionTLS12}
+	ts.StartTLS()
+	defer ts.Close()
+	caBundle := writePEM(t, dir, "ca.pem", "CERTIFICATE", ts.Certificate().Raw)
+
+	get := func(cfg NetworkConfig, url string) (*http.Response, error) {
+		transport, err := cfg.transport()
+		require.NoError(t, err)
+		return (&http.Client{Transport: transport}).Get(url)
+	}
+
+	// The server's certificate is only trusted with the CA bundle
+	_, err = get(NetworkConfig{}, ts.URL)
+	require.Error(t, err)
+	_, err = get(NetworkConfig{CABundlePaths: []string{caBundle}}, ts.URL)
+	require.Error(t, err, "the server requires a client certificate")
+
+	resp, err := get(NetworkConfig{CABundlePaths: []string{caBundle}, ClientCertificates: []ClientCertificate{clientCert}}, ts.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Requests go through the proxy, except to hosts in the no-proxy list
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+	}))
+	defer proxy.Close()
+
+	resp, err = get(NetworkConfig{ProxyURL: proxy.URL}, "http://github.example.invalid/api/v3/meta")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, []string{"http://github.example.invalid/api/v3/meta"}, proxied)
+
+	_, err = get(NetworkConfig{ProxyURL: proxy.URL, NoProxy: []string{".example.invalid"}}, "http://github.example.invalid/api/v3/meta")
+	require.Error(t, err, "the host does not resolve without the proxy")
+	assert.Len(t, proxied, 1)
+
+	// Invalid files are reported
+	_, err = NetworkConfig{CABundlePaths: []string{clientCert.KeyPath}}.transport()
+	require.ErrorContains(t, err, "no PEM certificates found in CA bundle")
+	_, err = NetworkConfig{ClientCertificates: []ClientCertificate{{CertPath: clientCert.CertPath, KeyPath: caBundle}}}.transport()
+	require.ErrorContains(t, err, "failed to load client certificate")
+
+	// Without settings, the default transport is used
+	transport, err := NetworkConfig{}.transport()
+	require.NoError(t, err)
+	assert.Same(t, http.DefaultTransport, transport)
+}
+
+func TestNewMCPServer_JobLogs(t *testing.T) {
+	ghServer := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/octo/hello-world/actions/jobs/1/logs":
+			assert.Equal(t, "Bearer ghp_test", r.Header.Get("Authorization"))
+			http.Redirect(w, r, "http://"+r.Host+"/download/job-1.txt?sig=abc", http.StatusFound)
+		case "/download/job-1.txt":
+			assert.Empty(t, r.Header.Get("Authorization"), "downloads are signed and get no credentials")
+			_, _ = w.Write([]byte("step 1\nstep 2\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}), MCPServerConfig{
+		EnabledToolsets: []string{"actions"},
+	})
+
+	result := callTool(t, ghServer, "get_job_logs", map[string]any{"owner": "octo", "repo": "hello-world", "job_id": 1})
+	require.False(t, result.IsError, result.Content)
+	assert.Contains(t, toolText(t, result), "/download/job-1.txt?sig=abc")
+
+	result = callTool(t, ghServer, "get_job_logs", map[string]any{"owner": "octo", "repo": "hello-world", "job_id": 1, "return_content": true})
+	require.False(t, result.IsError, result.Content)
+	assert.Contains(t, toolText(t, result), `"logs_content":"step 1\nstep 2"`)
+}
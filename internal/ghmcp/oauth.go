@@ -0,0 +1,471 @@
+package ghmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// OAuthConfig configures the OAuth device flow and where the resulting credentials are stored.
+type OAuthConfig struct {
+	// ClientID of the OAuth or GitHub App used for the device flow
+	ClientID string
+
+	// ClientSecret of the OAuth or GitHub App, only needed if the app requires it to refresh tokens
+	ClientSecret string
+
+	// Scopes to request, ignored by GitHub Apps which use their configured permissions instead
+	Scopes []string
+
+	// CredentialsPath is the path to the credential file, see DefaultCredentialsPath
+	CredentialsPath string
+}
+
+// DefaultCredentialsPath returns the default location of the credential file in the user's config directory.
+func DefaultCredentialsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config directory: %w", err)
+	}
+	return filepath.Join(dir, "github-mcp-server", "credentials.json"), nil
+}
+
+// storedCredential is a token obtained through the device flow, as persisted in the credential file.
+type storedCredential struct {
+	ClientID              string    `json:"client_id"`
+	AccessToken           string    `json:"access_token"`
+	TokenType             string    `json:"token_type,omitempty"`
+	Scope                 string    `json:"scope,omitempty"`
+	ExpiresAt             time.Time `json:"expires_at"`
+	RefreshToken          string    `json:"refresh_token,omitempty"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// credentialStore persists credentials per host in a JSON file only readable by the current user.
+type credentialStore struct {
+	path string
+}
+
+func (s *credentialStore) load() (map[string]storedCredential, error) {
+	credentials := map[string]storedCredential{}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return credentials, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credential file: %w", err)
+	}
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credential file: %w", err)
+	}
+	return credentials, nil
+}
+
+func (s *credentialStore) save(credentials map[string]storedCredential) error {
+	data, err := json.MarshalIndent(credentials, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create credential directory: %w", err)
+	}
+
+	// Write to a temporary file first so that a failed write never leaves a truncated credential file behind
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".credentials-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create credential file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to restrict credential file permissions: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	return nil
+}
+
+// get returns the credential stored for host, if any.
+func (s *credentialStore) get(host string) (storedCredential, bool, error) {
+	credentials, err := s.load()
+	if err != nil {
+		return storedCredential{}, false, err
+	}
+	credential, ok := credentials[host]
+	return credential, ok, nil
+}
+
+// put stores the credential for host, replacing any previous one.
+func (s *credentialStore) put(host string, credential storedCredential) error {
+	credentials, err := s.load()
+	if err != nil {
+		return err
+	}
+	credentials[host] = credential
+	return s.save(credentials)
+}
+
+// delete removes the credential for host, reporting whether there was one.
+func (s *credentialStore) delete(host string) (bool, error) {
+	credentials, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := credentials[host]; !ok {
+		return false, nil
+	}
+	delete(credentials, host)
+	return true, s.save(credentials)
+}
+
+// deviceCode is the response to a device authorization request.
+type deviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// tokenResponse is the response of the OAuth access token endpoint, which reports errors with a 200 status.
+type tokenResponse struct {
+	AccessToken           string `json:"access_token"`
+	TokenType             string `json:"token_type"`
+	Scope                 string `json:"scope"`
+	ExpiresIn             int    `json:"expires_in"`
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiresIn int    `json:"refresh_token_expires_in"`
+	Interval              int    `json:"interval"`
+	Error                 string `json:"error"`
+	ErrorDescription      string `json:"error_description"`
+}
+
+// deviceFlow implements the OAuth device authorization flow against a GitHub host.
+// See: https://docs.github.com/en/apps/oauth-apps/building-oauth-apps/authorizing-oauth-apps#device-flow
+type deviceFlow struct {
+	webURL       *url.URL
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+	now          func() time.Time
+	sleep        func(ctx context.Context, d time.Duration) error
+}
+
+func newDeviceFlow(host apiHost, clientID, clientSecret string, transport http.RoundTripper) *deviceFlow {
+	return &deviceFlow{
+		webURL:       host.webURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   &http.Client{Transport: transport},
+		now:          time.Now,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(d):
+				return nil
+			}
+		},
+	}
+}
+
+func (f *deviceFlow) post(ctx context.Context, path string, form url.Values, v any) error {
+	endpoint := f.webURL.JoinPath(path).String()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", endpoint, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s: %s", resp.StatusCode, endpoint, string(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// requestCode starts the device flow, returning the code the user has to enter.
+func (f *deviceFlow) requestCode(ctx context.Context, scopes []string) (deviceCode, error) {
+	var code deviceCode
+	err := f.post(ctx, "login/device/code", url.Values{
+		"client_id": {f.clientID},
+		"scope":     {strings.Join(scopes, " ")},
+	}, &code)
+	if err != nil {
+		return deviceCode{}, fmt.Errorf("failed to request device code: %w", err)
+	}
+	if code.DeviceCode == "" {
+		return deviceCode{}, fmt.Errorf("failed to request device code: no device code in response")
+	}
+	return code, nil
+}
+
+// pollToken polls until the user has authorized the device code, or the code has expired.
+func (f *deviceFlow) pollToken(ctx context.Context, code deviceCode) (storedCredential, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := f.now().Add(time.Duration(code.ExpiresIn) * time.Second)
+
+	for {
+		if err := f.sleep(ctx, interval); err != nil {
+			return storedCredential{}, err
+		}
+		if code.ExpiresIn > 0 && f.now().After(deadline) {
+			return storedCredential{}, fmt.Errorf("device code expired before it was authorized")
+		}
+
+		var resp tokenResponse
+		err := f.post(ctx, "login/oauth/access_token", url.Values{
+			"client_id":   {f.clientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &resp)
+		if err != nil {
+			return storedCredential{}, fmt.Errorf("failed to poll for access token: %w", err)
+		}
+
+		switch resp.Error {
+		case "":
+			return f.credentialFromResponse(resp)
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			if resp.Interval > 0 {
+				interval = time.Duration(resp.Interval) * time.Second
+			} else {
+				interval += 5 * time.Second
+			}
+			continue
+		default:
+			return storedCredential{}, fmt.Errorf("device authorization failed: %s: %s", resp.Error, resp.ErrorDescription)
+		}
+	}
+}
+
+// refresh exchanges a refresh token for a new access token.
+func (f *deviceFlow) refresh(ctx context.Context, refreshToken string) (storedCredential, error) {
+	form := url.Values{
+		"client_id":     {f.clientID},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+	if f.clientSecret != "" {
+		form.Set("client_secret", f.clientSecret)
+	}
+
+	var resp tokenResponse
+	if err := f.post(ctx, "login/oauth/access_token", form, &resp); err != nil {
+		return storedCredential{}, fmt.Errorf("failed to refresh access token: %w", err)
+	}
+	if resp.Error != "" {
+		return storedCredential{}, fmt.Errorf("failed to refresh access token: %s: %s", resp.Error, resp.ErrorDescription)
+	}
+	return f.credentialFromResponse(resp)
+}
+
+func (f *deviceFlow) credentialFromResponse(resp tokenResponse) (storedCredential, error) {
+	if resp.AccessToken == "" {
+		return storedCredential{}, fmt.Errorf("no access token in response")
+	}
+
+	credential := storedCredential{
+		ClientID:     f.clientID,
+		AccessToken:  resp.AccessToken,
+		TokenType:    resp.TokenType,
+		Scope:        resp.Scope,
+		RefreshToken: resp.RefreshToken,
+	}
+	// Only tokens of GitHub Apps with expiring user tokens enabled expire
+	now := f.now()
+	if resp.ExpiresIn > 0 {
+		credential.ExpiresAt = now.Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	if resp.RefreshTokenExpiresIn > 0 {
+		credential.RefreshTokenExpiresAt = now.Add(time.Duration(resp.RefreshTokenExpiresIn) * time.Second)
+	}
+	return credential, nil
+}
+
+// storedTokenSource provides the token from the credential store, refreshing and
+// persisting it again shortly before it expires.
+type storedTokenSource struct {
+	store *credentialStore
+	host  string
+	flow  *deviceFlow
+
+	mu         sync.Mutex
+	credential storedCredential
+}
+
+// newStoredTokenSource returns a token source for the credential stored for host, or nil if there is none.
+func newStoredTokenSource(cfg OAuthConfig, host apiHost, transport http.RoundTripper) (*storedTokenSource, error) {
+	store := &credentialStore{path: cfg.CredentialsPath}
+	credential, ok, err := store.get(host.webURL.String())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return &storedTokenSource{
+		store:      store,
+		host:       host.webURL.String(),
+		flow:       newDeviceFlow(host, credential.ClientID, cfg.ClientSecret, transport),
+		credential: credential,
+	}, nil
+}
+
+func (s *storedTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiring := !s.credential.ExpiresAt.IsZero() &&
+		s.flow.now().Add(tokenRefreshMargin).After(s.credential.ExpiresAt)
+	if !expiring {
+		return s.credential.AccessToken, nil
+	}
+
+	if s.credential.RefreshToken == "" {
+		return "", fmt.Errorf("stored GitHub token has expired, run the login command again")
+	}
+
+	credential, err := s.flow.refresh(ctx, s.credential.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	if err := s.store.put(s.host, credential); err != nil {
+		return "", err
+	}
+	s.credential = credential
+	return s.credential.AccessToken, nil
+}
+
+// HasStoredCredentials reports whether the login command has stored a credential for the host.
+func HasStoredCredentials(hostname string, credentialsPath string) (bool, error) {
+	host, err := parseAPIHost(hostname)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse API host: %w", err)
+	}
+	store := &credentialStore{path: credentialsPath}
+	_, ok, err := store.get(host.webURL.String())
+	return ok, err
+}
+
+type LoginConfig struct {
+	// GitHub Host to log in to (e.g. github.com or github.enterprise.com)
+	Host string
+
+	// OAuth configures the device flow and the credential file
+	OAuth OAuthConfig
+
+	// Network configures the certificate authorities, client certificates and proxy used to connect to GitHub
+	Network NetworkConfig
+}
+
+// RunLogin performs the OAuth device flow and stores the resulting token in the credential file.
+func RunLogin(cfg LoginConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.OAuth.ClientID == "" {
+		return fmt.Errorf("an OAuth client ID is required to log in")
+	}
+
+	host, err := parseAPIHost(cfg.Host)
+	if err != nil {
+		return fmt.Errorf("failed to parse API host: %w", err)
+	}
+
+	transport, err := cfg.Network.transport()
+	if err != nil {
+		return fmt.Errorf("failed to configure network: %w", err)
+	}
+
+	flow := newDeviceFlow(host, cfg.OAuth.ClientID, cfg.OAuth.ClientSecret, transport)
+	code, err := flow.requestCode(ctx, cfg.OAuth.Scopes)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Open %s in your browser and enter the code %s\n", code.VerificationURI, code.UserCode)
+
+	credential, err := flow.pollToken(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	store := &credentialStore{path: cfg.OAuth.CredentialsPath}
+	if err := store.put(host.webURL.String(), credential); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Logged in to %s, credentials stored in %s\n", host.webURL, cfg.OAuth.CredentialsPath)
+	return nil
+}
+
+type LogoutConfig struct {
+	// GitHub Host to log out of (e.g. github.com or github.enterprise.com)
+	Host string
+
+	// CredentialsPath is the path to the credential file
+	CredentialsPath string
+}
+
+// RunLogout removes the stored credential for the host from the credential file.
+// The token itself is not revoked, it can be revoked from the GitHub settings.
+func RunLogout(cfg LogoutConfig) error {
+	host, err := parseAPIHost(cfg.Host)
+	if err != nil {
+		return fmt.Errorf("failed to parse API host: %w", err)
+	}
+
+	store := &credentialStore{path: cfg.CredentialsPath}
+	deleted, err := store.delete(host.webURL.String())
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		_, _ = fmt.Fprintf(os.Stderr, "Not logged in to %s\n", host.webURL)
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Logged out of %s\n", host.webURL)
+	return nil
+}
@@ -0,0 +1,206 @@
+package ghmcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOAuthServer serves the device flow endpoints, answering token requests with the queued responses in order.
+func fakeOAuthServer(t *testing.T, tokenResponses ...map[string]any) (*httptest.Server, *[]url.Values) {
+	t.Helper()
+
+	var tokenRequests []url.Values
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/device/code", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client-id", r.PostForm.Get("client_id"))
+		assert.Equal(t, "repo read:org", r.PostForm.Get("scope"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "device-code",
+			"user_code":        "ABCD-1234",
+			"verification_uri": "https://github.com/login/device",
+			"expires_in":       900,
+			"interval":         5,
+		})
+	})
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		tokenRequests = append(tokenRequests, r.PostForm)
+		require.LessOrEqual(t, len(tokenRequests), len(tokenResponses), "unexpected token request")
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(tokenResponses[len(tokenRequests)-1])
+	})
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts, &tokenRequests
+}
+
+func testDeviceFlow(t *testing.T, serverURL string, now *time.Time) *deviceFlow {
+	t.Helper()
+
+	webURL, err := url.Parse(serverURL + "/")
+	require.NoError(t, err)
+
+	flow := newDeviceFlow(apiHost{webURL: webURL}, "client-id", "", http.DefaultTransport)
+	flow.now = func() time.Time { return *now }
+	flow.sleep = func(_ context.Context, d time.Duration) error {
+		*now = now.Add(d)
+		return nil
+	}
+	return flow
+}
+
+func TestDeviceFlow(t *testing.T) {
+	ts, tokenRequests := fakeOAuthServer(t,
+		map[string]any{"error": "authorization_pending"},
+		map[string]any{"error": "slow_down", "interval": 10},
+		map[string]any{
+			"access_token":             "ghu_token",
+			"token_type":               "bearer",
+			"scope":                    "repo,read:org",
+			"expires_in":               28800,
+			"refresh_token":            "ghr_refresh",
+			"refresh_token_expires_in": 15897600,
+		},
+	)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	start := now
+	flow := testDeviceFlow(t, ts.URL, &now)
+
+	code, err := flow.requestCode(context.Background(), []string{"repo", "read:org"})
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-1234", code.UserCode)
+
+	credential, err := flow.pollToken(context.Background(), code)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ghu_token", credential.AccessToken)
+	assert.Equal(t, "ghr_refresh", credential.RefreshToken)
+	assert.Equal(t, "client-id", credential.ClientID)
+	assert.Equal(t, now.Add(8*time.Hour), credential.ExpiresAt)
+	// 5s, 5s and then 10s after being asked to slow down
+	assert.Equal(t, 20*time.Second, now.Sub(start))
+
+	require.Len(t, *tokenRequests, 3)
+	assert.Equal(t, "device-code", (*tokenRequests)[0].Get("device_code"))
+	assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", (*tokenRequests)[0].Get("grant_type"))
+}
+
+func TestDeviceFlow_AccessDenied(t *testing.T) {
+	ts, _ := fakeOAuthServer(t,
+		map[string]any{"error": "access_denied", "error_description": "The user has denied your application access."},
+	)
+
+	now := time.Now()
+	flow := testDeviceFlow(t, ts.URL, &now)
+
+	_, err := flow.pollToken(context.Background(), deviceCode{DeviceCode: "device-code", ExpiresIn: 900, Interval: 5})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access_denied")
+}
+
+func TestCredentialStore(t *testing.T) {
+	store := &credentialStore{path: filepath.Join(t.TempDir(), "github-mcp-server", "credentials.json")}
+
+	_, ok, err := store.get("https://github.com/")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.put("https://github.com/", storedCredential{ClientID: "client-id", AccessToken: "ghu_dotcom"}))
+	require.NoError(t, store.put("https://ghes.example.com/", storedCredential{ClientID: "client-id", AccessToken: "ghu_ghes"}))
+
+	info, err := os.Stat(store.path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	credential, ok, err := store.get("https://github.com/")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "ghu_dotcom", credential.AccessToken)
+
+	deleted, err := store.delete("https://github.com/")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	_, ok, err = store.get("https://github.com/")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	credential, ok, err = store.get("https://ghes.example.com/")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "ghu_ghes", credential.AccessToken)
+}
+
+func TestStoredTokenSource_Refresh(t *testing.T) {
+	ts, tokenRequests := fakeOAuthServer(t,
+		map[string]any{
+			"access_token":  "ghu_new",
+			"expires_in":    28800,
+			"refresh_token": "ghr_new",
+		},
+	)
+
+	webURL, err := url.Parse(ts.URL + "/")
+	require.NoError(t, err)
+	host := apiHost{webURL: webURL}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := OAuthConfig{CredentialsPath: filepath.Join(t.TempDir(), "credentials.json")}
+	store := &credentialStore{path: cfg.CredentialsPath}
+	require.NoError(t, store.put(webURL.String(), storedCredential{
+		ClientID:     "client-id",
+		AccessToken:  "ghu_old",
+		ExpiresAt:    now.Add(time.Hour),
+		RefreshToken: "ghr_old",
+	}))
+
+	source, err := newStoredTokenSource(cfg, host, http.DefaultTransport)
+	require.NoError(t, err)
+	require.NotNil(t, source)
+	source.flow.now = func() time.Time { return now }
+
+	token, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ghu_old", token)
+
+	now = now.Add(58 * time.Minute)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ghu_new", token)
+
+	require.Len(t, *tokenRequests, 1)
+	assert.Equal(t, "refresh_token", (*tokenRequests)[0].Get("grant_type"))
+	assert.Equal(t, "ghr_old", (*tokenRequests)[0].Get("refresh_token"))
+
+	// The refreshed credential is persisted for the next run
+	stored, ok, err := store.get(webURL.String())
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "ghu_new", stored.AccessToken)
+	assert.Equal(t, "ghr_new", stored.RefreshToken)
+}
+
+func TestNewStoredTokenSource_NotLoggedIn(t *testing.T) {
+	host, err := newDotcomHost()
+	require.NoError(t, err)
+
+	source, err := newStoredTokenSource(OAuthConfig{CredentialsPath: filepath.Join(t.TempDir(), "credentials.json")}, host, http.DefaultTransport)
+	require.NoError(t, err)
+	assert.Nil(t, source)
+}
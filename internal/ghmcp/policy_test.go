@@ -0,0 +1,37 @@
+package ghmcp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/policy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMCPServer_Policy(t *testing.T) {
+	var requested []string
+	ghServer := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}), MCPServerConfig{
+		EnabledToolsets: []string{"repos", "issues"},
+		Policy: &policy.Policy{
+			Read:  policy.Rules{Allow: []string{"octo-org/*"}},
+			Write: policy.Rules{Deny: []string{"*/*"}},
+		},
+	})
+
+	result := callTool(t, ghServer, "create_issue", map[string]any{"owner": "octo-org", "repo": "service", "title": "Bug"})
+	assert.True(t, result.IsError)
+	assert.Equal(t, `repository policy denies write access to octo-org/service (matched "*/*")`, toolText(t, result))
+
+	response := sendRequest(context.Background(), t, ghServer, "resources/read", map[string]any{"uri": "repo://github/docs/contents/README.md"})
+	rpcErr, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Contains(t, rpcErr.Error.Message, "repository policy does not allow read access to github/docs")
+
+	assert.Empty(t, requested, "denied requests should not reach GitHub")
+}
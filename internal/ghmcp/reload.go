@@ -0,0 +1,237 @@
+package ghmcp
+
+import (
+	"context"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/policy"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
+)
+
+// ReloadableConfig holds the settings that are applied again when the configuration is reloaded
+// while the server runs.
+type ReloadableConfig struct {
+	// EnabledToolsets is a list of toolsets to enable
+	EnabledToolsets []string
+
+	// Tools, if not empty, limits the tools of the enabled toolsets to those named
+	Tools []string
+
+	// ExcludeTools lists tools of the enabled toolsets that are not offered
+	ExcludeTools []string
+
+	// PolicyPath, if set, is a JSON file restricting the repositories tools and resources can access
+	PolicyPath string
+
+	// Descriptions overrides tool descriptions by translation key
+	Descriptions map[string]string
+}
+
+// ReloadConfig configures reloading the configuration while the server runs.
+type ReloadConfig struct {
+	// Load reads the current settings, reloading is disabled if nil
+	Load func() (ReloadableConfig, error)
+
+	// Files are checked for changes along with the repository policy and the translations file
+	Files []string
+
+	// Interval between checks of the files for changes, zero only reloads on SIGHUP
+	Interval time.Duration
+}
+
+// currentPolicy holds the repository policy, which is replaced when the configuration is reloaded.
+type currentPolicy struct {
+	atomic.Pointer[policy.Policy]
+}
+
+// ToolMiddleware checks tool calls against the current policy, if there is one.
+func (c *currentPolicy) ToolMiddleware(isWrite func(tool string) bool, login func(ctx context.Context) string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			p := c.Load()
+			if p == nil {
+				return next(ctx, request)
+			}
+			return p.ToolMiddleware(isWrite, login)(next)(ctx, request)
+		}
+	}
+}
+
+// ResourceTemplateMiddleware checks resource reads against the current policy, if there is one.
+func (c *currentPolicy) ResourceTemplateMiddleware(next server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		p := c.Load()
+		if p == nil {
+			return next(ctx, request)
+		}
+		return p.ResourceTemplateMiddleware(next)(ctx, request)
+	}
+}
+
+// reloader applies reloaded settings to a running server.
+type reloader struct {
+	mu      sync.Mutex
+	server  *server.MCPServer
+	factory toolsetFactory
+	tools   *toolIndex
+	policy  *currentPolicy
+	// names are the tools registered with the server
+	names []string
+	// sessions are the sessions that enabled toolsets for themselves
+	sessions *github.SessionTools
+}
+
+// activeToolNames returns the names of the tools of the enabled toolsets of tsg and of dynamic,
+// which may be nil.
+func activeToolNames(tsg *toolsets.ToolsetGroup, dynamic *toolsets.Toolset) []string {
+	var names []string
+	for _, tool := range activeTools(tsg, dynamic) {
+		names = append(names, tool.Tool.Name)
+	}
+	return names
+}
+
+// activeTools returns the tools of the enabled toolsets of tsg and of dynamic, which may be nil.
+func activeTools(tsg *toolsets.ToolsetGroup, dynamic *toolsets.Toolset) []server.ServerTool {
+	var tools []server.ServerTool
+	for _, toolset := range tsg.Toolsets {
+		tools = append(tools, toolset.GetActiveTools()...)
+	}
+	if dynamic != nil {
+		tools = append(tools, dynamic.GetActiveTools()...)
+	}
+	return tools
+}
+
+// reload replaces the tools and the repository policy of the server with those created from cfg,
+// which notifies the clients that the tools changed. Invalid settings leave the server unchanged.
+//
+// Toolsets enabled with the dynamic tools are disabled again, both those enabled for all sessions
+// and those sessions enabled for themselves, whose tools would otherwise take precedence over the
+// reloaded ones. Resource templates and prompts are not reloaded.
+func (r *reloader) reload(cfg ReloadableConfig) error {
+	var repoPolicy *policy.Policy
+	if cfg.PolicyPath != "" {
+		var err error
+		repoPolicy, err = policy.Load(cfg.PolicyPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	t, _ := translations.TranslationHelperWithOverrides(cfg.Descriptions)
+	tsg, dynamic, err := r.factory.create(r.server, cfg.EnabledToolsets, cfg.Tools, cfg.ExcludeTools, t)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tools.set(tsg, dynamic)
+	r.policy.Store(repoPolicy)
+
+	// Tools are replaced before the ones that are gone are deleted, so that tools that stay are
+	// never missing.
+	tools := activeTools(tsg, dynamic)
+	r.server.AddTools(tools...)
+	names := activeToolNames(tsg, dynamic)
+	var removed []string
+	for _, name := range r.names {
+		if !slices.Contains(names, name) {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) > 0 {
+		r.server.DeleteTools(removed...)
+	}
+	// Adding the tools above told every session that the tools changed
+	r.sessions.Clear()
+	r.names = names
+	return nil
+}
+
+// fileState is what is compared to find out whether a file changed.
+type fileState struct {
+	exists  bool
+	modTime int64
+	size    int64
+}
+
+// statFiles returns the state of each of the files at paths.
+func statFiles(paths []string) map[string]fileState {
+	states := make(map[string]fileState, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			states[path] = fileState{exists: true, modTime: info.ModTime().UnixNano(), size: info.Size()}
+		} else {
+			states[path] = fileState{}
+		}
+	}
+	return states
+}
+
+// watch reloads the configuration when one of the watched files changes or the process receives
+// SIGHUP, until ctx is done. Failed reloads are logged and leave the current configuration in place.
+func (r *reloader) watch(ctx context.Context, cfg ReloadConfig, policyPath string, logger *logrus.Logger) {
+	watched := func(policyPath string) []string {
+		files := append(slices.Clone(cfg.Files), translations.ConfigFile)
+		if policyPath != "" {
+			files = append(files, policyPath)
+		}
+		return files
+	}
+	files := watched(policyPath)
+	states := statFiles(files)
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var tick <-chan time.Time
+	if cfg.Interval > 0 {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			logger.Infof("received SIGHUP, reloading configuration")
+		case <-tick:
+			current := statFiles(files)
+			if maps.Equal(current, states) {
+				continue
+			}
+			logger.Infof("configuration changed, reloading")
+		}
+
+		settings, err := cfg.Load()
+		if err == nil {
+			err = r.reload(settings)
+		}
+		if err != nil {
+			logger.Errorf("failed to reload configuration, keeping the current one: %v", err)
+		} else {
+			logger.Infof("reloaded configuration")
+			files = watched(settings.PolicyPath)
+		}
+		// Remember the failed state too, so that it is only reported once
+		states = statFiles(files)
+	}
+}
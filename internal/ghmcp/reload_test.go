@@ -0,0 +1,240 @@
+package ghmcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Reloader(t *testing.T) {
+	ghServer, serverReloader, err := newMCPServer(testServerConfig(t, nil, MCPServerConfig{
+		EnabledToolsets: []string{"context"},
+	}))
+	require.NoError(t, err)
+
+	tools := listTools(t, ghServer)
+	assert.Contains(t, tools, "get_me")
+	assert.NotContains(t, tools, "create_issue")
+	assert.Equal(t, "issues", serverReloader.tools.toolsetOf("add_sub_issue"))
+
+	policyPath := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(policyPath, []byte(`{"write": {"deny": ["*/*"]}}`), 0600))
+
+	err = serverReloader.reload(ReloadableConfig{
+		EnabledToolsets: []string{"issues"},
+		ExcludeTools:    []string{"add_sub_issue"},
+		PolicyPath:      policyPath,
+		Descriptions:    map[string]string{"TOOL_CREATE_ISSUE_DESCRIPTION": "Open an issue"},
+	})
+	require.NoError(t, err)
+
+	tools = listTools(t, ghServer)
+	assert.NotContains(t, tools, "get_me")
+	assert.NotContains(t, tools, "add_sub_issue")
+	assert.Equal(t, "Open an issue", tools["create_issue"].Description)
+	assert.True(t, serverReloader.tools.isWrite("create_issue"))
+	assert.Empty(t, serverReloader.tools.toolsetOf("add_sub_issue"), "tools that are gone are dropped from the index")
+
+	result := callTool(t, ghServer, "create_issue", map[string]any{"owner": "octo-org", "repo": "service", "title": "Bug"})
+	assert.True(t, result.IsError)
+	assert.Equal(t, `repository policy denies write access to octo-org/service (matched "*/*")`, toolText(t, result))
+
+	// Invalid settings keep the current configuration
+	err = serverReloader.reload(ReloadableConfig{EnabledToolsets: []string{"unknown"}})
+	require.Error(t, err)
+	err = serverReloader.reload(ReloadableConfig{EnabledToolsets: []string{"context"}, PolicyPath: filepath.Join(t.TempDir(), "missing.json")})
+	require.Error(t, err)
+	err = serverReloader.reload(ReloadableConfig{EnabledToolsets: []string{"context"}, Tools: []string{"unknown"}})
+	require.Error(t, err)
+
+	assert.Equal(t, tools, listTools(t, ghServer))
+	assert.NotNil(t, serverReloader.policy.Load())
+
+	require.NoError(t, serverReloader.reload(ReloadableConfig{EnabledToolsets: []string{"context"}, Tools: []string{"get_me"}}))
+	assert.False(t, serverReloader.tools.isWrite("create_issue"))
+	assert.Empty(t, serverReloader.tools.toolsetOf("create_issue"))
+}
+
+func Test_ReloaderSessionTools(t *testing.T) {
+	ghServer, serverReloader, err := newMCPServer(testServerConfig(t, nil, MCPServerConfig{
+		EnabledToolsets: []string{"context"},
+		DynamicToolsets: true,
+	}))
+	require.NoError(t, err)
+
+	// The session is not registered, like the sessions of the streamable HTTP transport that only
+	// send POST requests
+	session := &toolsSessionStub{watchSessionStub: watchSessionStub{notifications: make(chan mcp.JSONRPCNotification, 10)}}
+	ctx := ghServer.WithContext(context.Background(), session)
+	sessionTools := func() map[string]mcp.Tool {
+		response, ok := sendRequest(ctx, t, ghServer, "tools/list", map[string]any{}).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		tools := make(map[string]mcp.Tool)
+		for _, tool := range response.Result.(mcp.ListToolsResult).Tools {
+			tools[tool.Name] = tool
+		}
+		return tools
+	}
+
+	result := callToolWithContext(ctx, t, ghServer, "enable_toolset", map[string]any{"toolset": "issues"})
+	require.False(t, result.IsError, result.Content)
+	assert.Contains(t, sessionTools(), "create_issue")
+	assert.NotContains(t, listTools(t, ghServer), "create_issue", "the toolset is only enabled for the session")
+
+	err = serverReloader.reload(ReloadableConfig{
+		EnabledToolsets: []string{"context"},
+		ExcludeTools:    []string{"create_issue"},
+	})
+	require.NoError(t, err)
+
+	// The session's toolsets are disabled again, so that its old tools don't hide the reloaded ones
+	assert.Empty(t, session.GetSessionTools())
+	tools := sessionTools()
+	assert.NotContains(t, tools, "create_issue")
+	assert.Contains(t, tools, "get_me")
+}
+
+func Test_ReloaderStreamableHTTPSessionTools(t *testing.T) {
+	ghServer, serverReloader, err := newMCPServer(testServerConfig(t, nil, MCPServerConfig{
+		EnabledToolsets: []string{"context"},
+		DynamicToolsets: true,
+	}))
+	require.NoError(t, err)
+	ts := httptest.NewServer(server.NewStreamableHTTPServer(ghServer))
+	defer ts.Close()
+
+	resp, _ := postMCP(t, ts.URL, nil, "", "initialize", map[string]any{
+		"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+		"clientInfo":      map[string]any{"name": "test", "version": "1.0"},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	require.NotEmpty(t, sessionID)
+	sessionTools := func() []string {
+		_, message := postMCP(t, ts.URL, nil, sessionID, "tools/list", map[string]any{})
+		var response struct {
+			Result struct {
+				Tools []struct {
+					Name string `json:"name"`
+				} `json:"tools"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(message, &response))
+		var names []string
+		for _, tool := range response.Result.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	_, message := postMCP(t, ts.URL, nil, sessionID, "tools/call", map[string]any{"name": "enable_toolset", "arguments": map[string]any{"toolset": "issues"}})
+	require.Contains(t, string(message), "Toolset issues enabled")
+	require.Contains(t, sessionTools(), "create_issue")
+
+	err = serverReloader.reload(ReloadableConfig{
+		EnabledToolsets: []string{"context"},
+		ExcludeTools:    []string{"create_issue"},
+	})
+	require.NoError(t, err)
+
+	tools := sessionTools()
+	assert.NotContains(t, tools, "create_issue", "the tools the session enabled before the reload are gone")
+	assert.Contains(t, tools, "get_me")
+}
+
+func Test_ReloaderWatch(t *testing.T) {
+	ghServer, serverReloader, err := newMCPServer(testServerConfig(t, nil, MCPServerConfig{
+		EnabledToolsets: []string{"context"},
+	}))
+	require.NoError(t, err)
+
+	// Clients are told when the tools change
+	notifications := make(chan mcp.JSONRPCNotification, 10)
+	session := &watchSessionStub{notifications: notifications}
+	require.NoError(t, ghServer.RegisterSession(context.Background(), session))
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	var toolsets atomic.Value
+	toolsets.Store([]string{"context"})
+	var loads atomic.Int32
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go serverReloader.watch(ctx, ReloadConfig{
+		Load: func() (ReloadableConfig, error) {
+			loads.Add(1)
+			return ReloadableConfig{EnabledToolsets: toolsets.Load().([]string)}, nil
+		},
+		Files:    []string{configPath},
+		Interval: 10 * time.Millisecond,
+	}, "", logger)
+
+	// Give the watcher time to record the initial state of the files
+	time.Sleep(50 * time.Millisecond)
+	assert.Zero(t, loads.Load(), "nothing changed")
+
+	toolsets.Store([]string{"issues"})
+	require.NoError(t, os.WriteFile(configPath, []byte("toolsets: [issues]\n"), 0600))
+
+	select {
+	case notification := <-notifications:
+		assert.Equal(t, "notifications/tools/list_changed", notification.Method)
+	case <-time.After(5 * time.Second):
+		t.Fatal("tools were not reloaded")
+	}
+	serverReloader.mu.Lock()
+	defer serverReloader.mu.Unlock()
+	assert.Equal(t, int32(1), loads.Load())
+	assert.Contains(t, serverReloader.names, "create_issue")
+}
+
+// watchSessionStub is an initialized client session that collects notifications.
+type watchSessionStub struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *watchSessionStub) SessionID() string { return "watch" }
+func (s *watchSessionStub) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+func (s *watchSessionStub) Initialize()       {}
+func (s *watchSessionStub) Initialized() bool { return true }
+
+var _ server.ClientSession = (*watchSessionStub)(nil)
+
+// toolsSessionStub is an initialized client session that can hold tools of its own.
+type toolsSessionStub struct {
+	watchSessionStub
+	mu    sync.Mutex
+	tools map[string]server.ServerTool
+}
+
+func (s *toolsSessionStub) SessionID() string { return "tools" }
+func (s *toolsSessionStub) GetSessionTools() map[string]server.ServerTool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tools
+}
+func (s *toolsSessionStub) SetSessionTools(tools map[string]server.ServerTool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools = tools
+}
+
+var _ server.SessionWithTools = (*toolsSessionStub)(nil)
@@ -5,17 +5,30 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"maps"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
+	"github.com/github/github-mcp-server/pkg/audit"
+	"github.com/github/github-mcp-server/pkg/budget"
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	"github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/httpcache"
 	mcplog "github.com/github/github-mcp-server/pkg/log"
+	"github.com/github/github-mcp-server/pkg/metrics"
+	"github.com/github/github-mcp-server/pkg/policy"
+	"github.com/github/github-mcp-server/pkg/ratelimit"
 	"github.com/github/github-mcp-server/pkg/raw"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/tracing"
 	"github.com/github/github-mcp-server/pkg/translations"
 	gogithub "github.com/google/go-github/v73/github"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -31,13 +44,29 @@ type MCPServerConfig struct {
 	// GitHub Host to target for API requests (e.g. github.com or github.enterprise.com)
 	Host string
 
+	// HostOverrides explicitly sets individual API URLs instead of deriving them from Host
+	HostOverrides APIHostOverrides
+
 	// GitHub Token to authenticate with the GitHub API
 	Token string
 
+	// GitHubApp configures authentication as a GitHub App installation instead of Token
+	GitHubApp GitHubAppConfig
+
+	// OAuth configures the credential file written by the login command, which is used
+	// when neither Token nor GitHubApp are set
+	OAuth OAuthConfig
+
 	// EnabledToolsets is a list of toolsets to enable
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
 	EnabledToolsets []string
 
+	// Tools, if not empty, limits the tools of the enabled toolsets to those named
+	Tools []string
+
+	// ExcludeTools lists tools of the enabled toolsets that are not offered
+	ExcludeTools []string
+
 	// Whether to enable dynamic toolsets
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#dynamic-tool-discovery
 	DynamicToolsets bool
@@ -45,32 +74,193 @@ type MCPServerConfig struct {
 	// ReadOnly indicates if we should only offer read-only tools
 	ReadOnly bool
 
+	// DryRun answers the GitHub requests of write tools that would change data instead of sending
+	// them, and reports them as the result of the tool
+	DryRun bool
+
 	// Translator provides translated text for the server tooling
 	Translator translations.TranslationHelperFunc
+
+	// RateLimit configures how requests rejected by GitHub's rate limits are retried
+	RateLimit RateLimitConfig
+
+	// Cache configures the conditional request cache for REST API reads
+	Cache CacheConfig
+
+	// Metrics, if set, records tool calls and GitHub API requests
+	Metrics *metrics.Metrics
+
+	// Tracer, if set, creates spans for MCP requests and the GitHub API requests they make
+	Tracer *tracing.Tracer
+
+	// Auditor, if set, records calls of write tools
+	Auditor *audit.Auditor
+
+	// Policy, if set, restricts the repositories tools and resources can access
+	Policy *policy.Policy
+
+	// Logger receives operational messages such as rate limit retries, defaults to discarding them
+	Logger *logrus.Logger
+
+	// Hosts are additional GitHub hosts that tools can be called against with their host argument
+	Hosts []HostConfig
+
+	// Network configures the certificate authorities, client certificates and proxy used to connect to GitHub
+	Network NetworkConfig
+
+	// Budget limits the size of tool results, which are truncated with continuation tokens for the rest
+	Budget budget.Config
+
+	// OutputFormat is the format JSON tool results are rendered in unless a call asks for another one,
+	// defaults to compact JSON
+	OutputFormat github.OutputFormat
+}
+
+// serverHost is a GitHub host the server makes requests to.
+type serverHost struct {
+	api     apiHost
+	clients *clientCache
+	// tokens are the server's own credentials for the host, nil if it has none
+	tokens tokenSource
+	// version is the release of GitHub Enterprise Server the host runs, empty for other hosts
+	// and if it is unknown
+	version string
+}
+
+// RateLimitConfig configures retries of requests rejected by rate limits or transient server errors.
+type RateLimitConfig struct {
+	// MaxRetries is the maximum number of times a request is retried, zero disables retries
+	MaxRetries int
+
+	// MaxWait is the maximum total time spent waiting to retry a single request
+	MaxWait time.Duration
+}
+
+// CacheConfig configures the cache of REST API responses, which are revalidated with
+// conditional requests that don't count against the rate limit.
+type CacheConfig struct {
+	// MaxSize is the maximum total size of cached responses in bytes, zero disables the cache
+	MaxSize int64
+
+	// Dir, if set, persists cached responses in this directory instead of keeping them in memory
+	Dir string
+}
+
+// newStore creates the store for cached responses, or returns nil if the cache is disabled.
+func (c CacheConfig) newStore() (httpcache.Store, error) {
+	switch {
+	case c.MaxSize <= 0:
+		return nil, nil
+	case c.Dir != "":
+		return httpcache.NewDiskStore(c.Dir, c.MaxSize)
+	default:
+		return httpcache.NewMemoryStore(c.MaxSize), nil
+	}
 }
 
 func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
+	ghServer, _, err := newMCPServer(cfg)
+	return ghServer, err
+}
+
+// newMCPServer creates the server like NewMCPServer, along with the reloader that applies changes
+// of the configuration to it.
+func newMCPServer(cfg MCPServerConfig) (*server.MCPServer, *reloader, error) {
 	apiHost, err := parseAPIHost(cfg.Host)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse API host: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse API host: %w", err)
+	}
+	apiHost, err = apiHost.withOverrides(cfg.HostOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	networkTransport, err := cfg.Network.transport()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to configure network: %w", err)
 	}
 
-	// Construct our REST client
-	restClient := gogithub.NewClient(nil).WithAuthToken(cfg.Token)
-	restClient.UserAgent = fmt.Sprintf("github-mcp-server/%s", cfg.Version)
-	restClient.BaseURL = apiHost.baseRESTURL
-	restClient.UploadURL = apiHost.uploadURL
+	// The server's own credentials, used whenever a request does not carry a token
+	var serverTokens tokenSource
+	switch {
+	case cfg.GitHubApp.Enabled():
+		serverTokens, err = newInstallationTokenSource(cfg.GitHubApp, apiHost, networkTransport)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to configure GitHub App authentication: %w", err)
+		}
+	case cfg.Token != "":
+		serverTokens = staticTokenSource(cfg.Token)
+	case cfg.OAuth.CredentialsPath != "":
+		storedTokens, err := newStoredTokenSource(cfg.OAuth, apiHost, networkTransport)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load stored credentials: %w", err)
+		}
+		if storedTokens != nil {
+			serverTokens = storedTokens
+		}
+	}
 
-	// Construct our GraphQL client
-	// We're using NewEnterpriseClient here unconditionally as opposed to NewClient because we already
-	// did the necessary API host parsing so that github.com will return the correct URL anyway.
-	gqlHTTPClient := &http.Client{
-		Transport: &bearerAuthTransport{
-			transport: http.DefaultTransport,
-			token:     cfg.Token,
-		},
-	} // We're going to wrap the Transport later in beforeInit
-	gqlClient := githubv4.NewEnterpriseClient(apiHost.graphqlURL.String(), gqlHTTPClient)
+	logger := cfg.Logger
+	if logger == nil {
+		logger = logrus.New()
+		logger.SetOutput(io.Discard)
+	}
+
+	cacheStore, err := cfg.Cache.newStore()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create response cache: %w", err)
+	}
+
+	// The default host, along with any additional ones, by name
+	if err := validateHosts(cfg.Hosts); err != nil {
+		return nil, nil, err
+	}
+	hosts := map[string]*serverHost{
+		DefaultHostName: {api: apiHost, clients: newHostClients(cfg, apiHost, networkTransport, cacheStore, logger), tokens: serverTokens},
+	}
+	hostNames := []string{DefaultHostName}
+	for _, host := range cfg.Hosts {
+		hostAPI, err := parseAPIHost(host.Host)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse API host of %s: %w", host.Name, err)
+		}
+		hosts[host.Name] = &serverHost{api: hostAPI, clients: newHostClients(cfg, hostAPI, networkTransport, cacheStore, logger), tokens: staticTokenSource(host.Token)}
+		hostNames = append(hostNames, host.Name)
+	}
+
+	// Tools that the release of GitHub Enterprise Server does not have are hidden, or rejected for
+	// additional hosts. If the release cannot be looked up, all tools are offered.
+	for _, name := range hostNames {
+		host := hosts[name]
+		host.version, err = host.detectVersion(context.Background())
+		if err != nil {
+			logger.Warnf("failed to detect the GitHub Enterprise Server version of host %s, offering all tools: %v", name, err)
+		} else if host.version != "" {
+			logger.Infof("host %s runs GitHub Enterprise Server %s", name, host.version)
+		}
+	}
+
+	// clientsFor returns the clients for the host and the token of the current request. A token sent
+	// with the request is used for the default host, and a token sent for an additional host for
+	// that host. The server's own credentials are only used for requests that carry no token, so
+	// that callers with a token of their own never act as the server.
+	clientsFor := func(ctx context.Context) (*githubClients, error) {
+		name := hostFromContext(ctx)
+		host := hosts[name]
+		if hostToken, ok := hostTokenFromContext(ctx, name); ok && name != DefaultHostName {
+			return host.clients.get(hostToken, staticTokenSource(hostToken)), nil
+		}
+		if requestToken, ok := tokenFromContext(ctx); ok {
+			if name != DefaultHostName {
+				return nil, fmt.Errorf("no GitHub token provided for host %s, send one in the %s%s header", name, HostTokenHeaderPrefix, name)
+			}
+			return host.clients.get(requestToken, staticTokenSource(requestToken)), nil
+		}
+		if host.tokens == nil {
+			return nil, fmt.Errorf("no GitHub token provided")
+		}
+		return host.clients.get(serverCredentialsKey, host.tokens), nil
+	}
 
 	// When a client send an initialize request, update the user agent to include the client info.
 	beforeInit := func(_ context.Context, _ any, message *mcp.InitializeRequest) {
@@ -81,11 +271,8 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 			message.Params.ClientInfo.Version,
 		)
 
-		restClient.UserAgent = userAgent
-
-		gqlHTTPClient.Transport = &userAgentTransport{
-			transport: gqlHTTPClient.Transport,
-			agent:     userAgent,
+		for _, host := range hosts {
+			host.clients.setUserAgent(userAgent)
 		}
 	}
 
@@ -100,25 +287,91 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 		},
 	}
 
-	ghServer := github.NewServer(cfg.Version, server.WithHooks(hooks))
+	// Sessions are tracked so that the toolsets they enabled for themselves are disabled on reload
+	sessions := github.NewSessionTools()
+	sessions.AddHooks(hooks)
 
-	enabledToolsets := cfg.EnabledToolsets
-	if cfg.DynamicToolsets {
-		// filter "all" from the enabled toolsets
-		enabledToolsets = make([]string, 0, len(cfg.EnabledToolsets))
-		for _, toolset := range cfg.EnabledToolsets {
-			if toolset != "all" {
-				enabledToolsets = append(enabledToolsets, toolset)
-			}
-		}
+	// tools is filled in once the toolsets have been created, before any tool can be called
+	tools := newToolIndex()
+	if cfg.Metrics != nil {
+		cfg.Metrics.AddHooks(hooks)
 	}
 
-	getClient := func(_ context.Context) (*gogithub.Client, error) {
-		return restClient, nil // closing over client
+	serverOpts := []server.ServerOption{server.WithHooks(hooks)}
+	if cfg.Tracer != nil {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(cfg.Tracer.ToolMiddleware))
+	}
+	if cfg.Metrics != nil {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(cfg.Metrics.ToolMiddleware(tools.toolsetOf)))
+	}
+	if len(cfg.Hosts) > 0 {
+		// The host is selected before the middleware that makes requests on behalf of the tool call
+		serverOpts = append(serverOpts,
+			server.WithToolHandlerMiddleware(hostToolMiddleware(hostNames, func(request mcp.CallToolRequest, host string) error {
+				return tools.checkGHESVersion(request.Params.Name, request.GetArguments(), hosts[host].version)
+			})),
+			server.WithToolFilter(hostToolFilter(hostNames, func(tool, host string) error {
+				return tools.checkGHESVersion(tool, nil, hosts[host].version)
+			})),
+		)
+	}
+	if cfg.Auditor != nil {
+		cfg.Auditor.AddHooks(hooks)
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(cfg.Auditor.ToolMiddleware(
+			tools.isWrite,
+			loginFor(clientsFor),
+			cfg.DryRun,
+		)))
+	}
+	// The policy is checked even if there is none yet, as one may be set when the configuration is
+	// reloaded. It is added after the auditor, so that denied calls are audited too.
+	repoPolicy := &currentPolicy{}
+	repoPolicy.Store(cfg.Policy)
+	policyMiddleware := repoPolicy.ToolMiddleware(tools.isWrite, loginFor(clientsFor))
+	if cfg.Budget.Enabled() {
+		// Continuation calls are answered from the stored result without reaching the policy below,
+		// so it is checked again with the arguments of the first call
+		resultBudget := budget.New(cfg.Budget)
+		resultBudget.CheckContinuations(policyMiddleware)
+		serverOpts = append(serverOpts,
+			server.WithToolHandlerMiddleware(resultBudget.ToolMiddleware),
+			server.WithToolFilter(resultBudget.ToolFilter),
+		)
+	}
+	// Results are rendered before they are truncated to the budget, so that continuations are in the same format
+	renderer := github.NewRenderer(cfg.OutputFormat)
+	serverOpts = append(serverOpts,
+		server.WithToolHandlerMiddleware(renderer.ToolMiddleware),
+		server.WithToolFilter(renderer.ToolFilter),
+	)
+	// Parts of results are selected before they are rendered, as the select path applies to JSON
+	serverOpts = append(serverOpts,
+		server.WithToolHandlerMiddleware(github.SelectToolMiddleware(tools.isWrite)),
+		server.WithToolFilter(github.SelectToolFilter),
+	)
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(policyMiddleware))
+	if cfg.DryRun {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(dryrun.ToolMiddleware(
+			tools.isWrite,
+		)))
+	}
+
+	ghServer := github.NewServer(cfg.Version, serverOpts...)
+
+	getClient := func(ctx context.Context) (*gogithub.Client, error) {
+		c, err := clientsFor(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return c.rest, nil
 	}
 
-	getGQLClient := func(_ context.Context) (*githubv4.Client, error) {
-		return gqlClient, nil // closing over client
+	getGQLClient := func(ctx context.Context) (*githubv4.Client, error) {
+		c, err := clientsFor(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return c.gql, nil
 	}
 
 	getRawClient := func(ctx context.Context) (*raw.Client, error) {
@@ -126,26 +379,209 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 		}
-		return raw.NewClient(client, apiHost.rawURL), nil // closing over client
+		return raw.NewClient(client, hosts[hostFromContext(ctx)].api.rawURL), nil // closing over client
 	}
 
-	// Create default toolsets
-	tsg := github.DefaultToolsetGroup(cfg.ReadOnly, getClient, getGQLClient, getRawClient, cfg.Translator)
-	err = tsg.EnableToolsets(enabledToolsets)
+	// Downloads from URLs handed out by the API, such as job logs, are signed and must not carry
+	// the credentials of the API clients, but use the same certificates and proxy
+	downloadClient := &http.Client{Transport: networkTransport}
+	getHTTPClient := func(_ context.Context) (*http.Client, error) {
+		return downloadClient, nil
+	}
 
+	factory := toolsetFactory{
+		readOnly:      cfg.ReadOnly,
+		dynamic:       cfg.DynamicToolsets,
+		sessions:      sessions,
+		getClient:     getClient,
+		getGQLClient:  getGQLClient,
+		getRawClient:  getRawClient,
+		getHTTPClient: getHTTPClient,
+	}
+	if len(cfg.Hosts) == 0 {
+		// With several hosts, all tools are registered and the host middleware and filter check the
+		// release of each host instead
+		factory.ghesVersion = hosts[DefaultHostName].version
+	}
+	tsg, dynamic, err := factory.create(ghServer, cfg.EnabledToolsets, cfg.Tools, cfg.ExcludeTools, cfg.Translator)
 	if err != nil {
-		return nil, fmt.Errorf("failed to enable toolsets: %w", err)
+		return nil, nil, err
 	}
+	if cfg.Tracer != nil {
+		tsg.UseResourceTemplateMiddleware(cfg.Tracer.ResourceTemplateMiddleware)
+	}
+	tsg.UseResourceTemplateMiddleware(repoPolicy.ResourceTemplateMiddleware)
 
 	// Register all mcp functionality with the server
 	tsg.RegisterAll(ghServer)
-
-	if cfg.DynamicToolsets {
-		dynamic := github.InitDynamicToolset(ghServer, tsg, cfg.Translator)
+	if dynamic != nil {
 		dynamic.RegisterTools(ghServer)
 	}
+	tools.set(tsg, dynamic)
+
+	return ghServer, &reloader{
+		server:   ghServer,
+		factory:  factory,
+		tools:    tools,
+		policy:   repoPolicy,
+		names:    activeToolNames(tsg, dynamic),
+		sessions: sessions,
+	}, nil
+}
 
-	return ghServer, nil
+// newHostClients creates the cache of clients for the API of host, whose transports are built on
+// baseTransport and share the response cache store, which may be nil.
+func newHostClients(cfg MCPServerConfig, host apiHost, baseTransport http.RoundTripper, cacheStore httpcache.Store, logger *logrus.Logger) *clientCache {
+	if cfg.Metrics != nil {
+		// Record every attempt as it is sent, including retries and revalidations
+		baseTransport = cfg.Metrics.Transport(baseTransport, host.classify)
+	}
+	if cfg.Tracer != nil {
+		baseTransport = cfg.Tracer.Transport(baseTransport, host.classify)
+	}
+
+	transport := ratelimit.NewTransport(baseTransport, ratelimit.Options{
+		MaxRetries: cfg.RateLimit.MaxRetries,
+		MaxWait:    cfg.RateLimit.MaxWait,
+		OnRate: func(req *http.Request, rate ratelimit.Rate) {
+			logger.Debugf("GitHub rate limit for %s %s: %d of %d remaining for %s, resets at %s",
+				req.Method, req.URL.Path, rate.Remaining, rate.Limit, rate.Resource, rate.Reset.Format(time.RFC3339))
+		},
+		OnRetry: func(req *http.Request, resp *http.Response, attempt int, wait time.Duration) {
+			logger.Warnf("GitHub responded %d to %s %s, retrying in %s (attempt %d of %d)",
+				resp.StatusCode, req.Method, req.URL.Path, wait.Round(time.Millisecond), attempt, cfg.RateLimit.MaxRetries)
+		},
+	})
+
+	transports := clientTransports{rest: transport, graphql: transport}
+	if cacheStore != nil {
+		// GraphQL requests are POSTs, so only REST reads can be cached. The hosts can share the
+		// store, as responses are keyed by their full URL, which includes the host, along with the
+		// Authorization, Accept and X-GitHub-Api-Version headers, which keep the responses of
+		// different tokens and media types apart.
+		transports.rest = httpcache.NewTransport(transport, cacheStore)
+	}
+	if cfg.DryRun {
+		transports.rest = dryrun.Transport(transports.rest, host.classify)
+		transports.graphql = dryrun.Transport(transports.graphql, host.classify)
+	}
+
+	return newClientCache(host, transports, fmt.Sprintf("github-mcp-server/%s", cfg.Version))
+
+}
+
+// toolsetFactory creates the toolsets of the server, when it starts and again when its
+// configuration is reloaded.
+type toolsetFactory struct {
+	readOnly bool
+	dynamic  bool
+	// sessions records the sessions that enabled toolsets for themselves with the dynamic tools
+	sessions     *github.SessionTools
+	getClient    github.GetClientFn
+	getGQLClient github.GetGQLClientFn
+	getRawClient raw.GetRawClientFn
+	// getHTTPClient returns the client for downloads, which sends no credentials
+	getHTTPClient github.GetHTTPClientFn
+	// ghesVersion, if set, is the release of GitHub Enterprise Server the tools are offered for
+	ghesVersion string
+}
+
+// create returns the toolsets with the named toolsets enabled and their tools filtered. In dynamic
+// mode, it also returns the dynamic toolset used to enable the others, and nil otherwise.
+func (f toolsetFactory) create(s *server.MCPServer, enabledToolsets, include, exclude []string, t translations.TranslationHelperFunc) (*toolsets.ToolsetGroup, *toolsets.Toolset, error) {
+	if f.dynamic {
+		// filter "all" from the enabled toolsets
+		names := make([]string, 0, len(enabledToolsets))
+		for _, toolset := range enabledToolsets {
+			if toolset != "all" {
+				names = append(names, toolset)
+			}
+		}
+		enabledToolsets = names
+	}
+
+	// Create default toolsets
+	tsg := github.DefaultToolsetGroup(f.readOnly, f.getClient, f.getGQLClient, f.getRawClient, f.getHTTPClient, t)
+	if err := tsg.EnableToolsets(enabledToolsets); err != nil {
+		return nil, nil, fmt.Errorf("failed to enable toolsets: %w", err)
+	}
+	if err := tsg.FilterTools(include, exclude); err != nil {
+		return nil, nil, fmt.Errorf("failed to filter tools: %w", err)
+	}
+	tsg.SetGHESVersion(f.ghesVersion)
+
+	if !f.dynamic {
+		return tsg, nil, nil
+	}
+	return tsg, github.InitDynamicToolset(s, tsg, f.sessions, t), nil
+}
+
+// toolIndex records facts about tools by name, for the hooks and middleware that only see tool names.
+type toolIndex struct {
+	mu sync.RWMutex
+	// toolset is the name of the toolset each tool belongs to
+	toolset map[string]string
+	// write holds the tools added with AddWriteTools, which are never annotated as read-only
+	write map[string]bool
+	// toolsets holds the toolset of each tool
+	toolsets map[string]*toolsets.Toolset
+}
+
+func newToolIndex() *toolIndex {
+	return &toolIndex{
+		toolset:  make(map[string]string),
+		write:    make(map[string]bool),
+		toolsets: make(map[string]*toolsets.Toolset),
+	}
+}
+
+// set records the tools of tsg and of dynamic, which may be nil, replacing those recorded before.
+func (i *toolIndex) set(tsg *toolsets.ToolsetGroup, dynamic *toolsets.Toolset) {
+	sets := maps.Clone(tsg.Toolsets)
+	if dynamic != nil {
+		sets[dynamic.Name] = dynamic
+	}
+	toolset := make(map[string]string)
+	write := make(map[string]bool)
+	toolsetsByTool := make(map[string]*toolsets.Toolset)
+	for name, ts := range sets {
+		for _, tool := range ts.GetAvailableTools() {
+			toolset[tool.Tool.Name] = name
+			toolsetsByTool[tool.Tool.Name] = ts
+			if readOnly := tool.Tool.Annotations.ReadOnlyHint; readOnly == nil || !*readOnly {
+				write[tool.Tool.Name] = true
+			}
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.toolset, i.write, i.toolsets = toolset, write, toolsetsByTool
+}
+
+// toolsetOf returns the name of the toolset the tool belongs to.
+func (i *toolIndex) toolsetOf(tool string) string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.toolset[tool]
+}
+
+// checkGHESVersion returns an error if the release of GitHub Enterprise Server does not have the
+// tool, or one of the arguments of a call.
+func (i *toolIndex) checkGHESVersion(tool string, arguments map[string]any, version string) error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if toolset, ok := i.toolsets[tool]; ok {
+		return toolset.CheckGHESVersion(tool, arguments, version)
+	}
+	return nil
+}
+
+// isWrite reports whether the tool may write to GitHub.
+func (i *toolIndex) isWrite(tool string) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.write[tool]
 }
 
 type StdioServerConfig struct {
@@ -155,13 +591,29 @@ type StdioServerConfig struct {
 	// GitHub Host to target for API requests (e.g. github.com or github.enterprise.com)
 	Host string
 
+	// HostOverrides explicitly sets individual API URLs instead of deriving them from Host
+	HostOverrides APIHostOverrides
+
 	// GitHub Token to authenticate with the GitHub API
 	Token string
 
+	// GitHubApp configures authentication as a GitHub App installation instead of Token
+	GitHubApp GitHubAppConfig
+
+	// OAuth configures the credential file written by the login command, which is used
+	// when neither Token nor GitHubApp are set
+	OAuth OAuthConfig
+
 	// EnabledToolsets is a list of toolsets to enable
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
 	EnabledToolsets []string
 
+	// Tools, if not empty, limits the tools of the enabled toolsets to those named
+	Tools []string
+
+	// ExcludeTools lists tools of the enabled toolsets that are not offered
+	ExcludeTools []string
+
 	// Whether to enable dynamic toolsets
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#dynamic-tool-discovery
 	DynamicToolsets bool
@@ -169,15 +621,56 @@ type StdioServerConfig struct {
 	// ReadOnly indicates if we should only register read-only tools
 	ReadOnly bool
 
+	// DryRun reports the changes write tools would make instead of making them
+	DryRun bool
+
 	// ExportTranslations indicates if we should export translations
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#i18n--overriding-descriptions
 	ExportTranslations bool
 
+	// Descriptions overrides tool descriptions by translation key, taking precedence over the
+	// translations file but not over GITHUB_MCP_ env vars
+	Descriptions map[string]string
+
 	// EnableCommandLogging indicates if we should log commands
 	EnableCommandLogging bool
 
 	// Path to the log file if not stderr
 	LogFilePath string
+
+	// RateLimit configures how requests rejected by GitHub's rate limits are retried
+	RateLimit RateLimitConfig
+
+	// Cache configures the conditional request cache for REST API reads
+	Cache CacheConfig
+
+	// MetricsAddress, if set, is the address to serve Prometheus metrics on at /metrics
+	MetricsAddress string
+
+	// Tracing configures exporting OpenTelemetry traces
+	Tracing TracingConfig
+
+	// Audit configures the audit log of write tool calls
+	Audit AuditConfig
+
+	// PolicyPath, if set, is a JSON file restricting the repositories tools and resources can access
+	PolicyPath string
+
+	// Reload configures reloading the configuration while the server runs
+	Reload ReloadConfig
+
+	// Hosts are additional GitHub hosts that tools can be called against with their host argument
+	Hosts []HostConfig
+
+	// Network configures the certificate authorities, client certificates and proxy used to connect to GitHub
+	Network NetworkConfig
+
+	// Budget limits the size of tool results, which are truncated with continuation tokens for the rest
+	Budget budget.Config
+
+	// OutputFormat is the format JSON tool results are rendered in unless a call asks for another one,
+	// defaults to compact JSON
+	OutputFormat github.OutputFormat
 }
 
 // RunStdioServer is not concurrent safe.
@@ -186,33 +679,79 @@ func RunStdioServer(cfg StdioServerConfig) error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	t, dumpTranslations := translations.TranslationHelper()
+	t, dumpTranslations := translations.TranslationHelperWithOverrides(cfg.Descriptions)
+
+	logrusLogger, err := newLogger(cfg.LogFilePath)
+	if err != nil {
+		return err
+	}
+
+	var serverMetrics *metrics.Metrics
+	if cfg.MetricsAddress != "" {
+		serverMetrics = metrics.New()
+		metricsServer, err := startMetricsServer(cfg.MetricsAddress, serverMetrics, logrusLogger)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = metricsServer.Close() }()
+	}
+
+	tracer, stopTracing, err := startTracing(ctx, cfg.Tracing, cfg.Version)
+	if err != nil {
+		return err
+	}
+	defer stopTracing()
+
+	auditor, stopAudit, err := startAudit(cfg.Audit)
+	if err != nil {
+		return err
+	}
+	defer stopAudit()
+
+	var repoPolicy *policy.Policy
+	if cfg.PolicyPath != "" {
+		repoPolicy, err = policy.Load(cfg.PolicyPath)
+		if err != nil {
+			return err
+		}
+	}
 
-	ghServer, err := NewMCPServer(MCPServerConfig{
+	ghServer, serverReloader, err := newMCPServer(MCPServerConfig{
 		Version:         cfg.Version,
 		Host:            cfg.Host,
+		HostOverrides:   cfg.HostOverrides,
 		Token:           cfg.Token,
+		GitHubApp:       cfg.GitHubApp,
+		OAuth:           cfg.OAuth,
 		EnabledToolsets: cfg.EnabledToolsets,
+		Tools:           cfg.Tools,
+		ExcludeTools:    cfg.ExcludeTools,
 		DynamicToolsets: cfg.DynamicToolsets,
 		ReadOnly:        cfg.ReadOnly,
+		DryRun:          cfg.DryRun,
 		Translator:      t,
+		RateLimit:       cfg.RateLimit,
+		Cache:           cfg.Cache,
+		Metrics:         serverMetrics,
+		Tracer:          tracer,
+		Auditor:         auditor,
+		Policy:          repoPolicy,
+		Logger:          logrusLogger,
+		Hosts:           cfg.Hosts,
+		Network:         cfg.Network,
+		Budget:          cfg.Budget,
+		OutputFormat:    cfg.OutputFormat,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create MCP server: %w", err)
 	}
 
-	stdioServer := server.NewStdioServer(ghServer)
+	if cfg.Reload.Load != nil {
+		go serverReloader.watch(ctx, cfg.Reload, cfg.PolicyPath, logrusLogger)
+	}
 
-	logrusLogger := logrus.New()
-	if cfg.LogFilePath != "" {
-		file, err := os.OpenFile(cfg.LogFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
-		if err != nil {
-			return fmt.Errorf("failed to open log file: %w", err)
-		}
+	stdioServer := server.NewStdioServer(ghServer)
 
-		logrusLogger.SetLevel(logrus.DebugLevel)
-		logrusLogger.SetOutput(file)
-	}
 	stdLogger := log.New(logrusLogger.Writer(), "stdioserver", 0)
 	stdioServer.SetErrorLogger(stdLogger)
 
@@ -251,11 +790,306 @@ func RunStdioServer(cfg StdioServerConfig) error {
 	return nil
 }
 
+type HTTPServerConfig struct {
+	// Version of the server
+	Version string
+
+	// GitHub Host to target for API requests (e.g. github.com or github.enterprise.com)
+	Host string
+
+	// HostOverrides explicitly sets individual API URLs instead of deriving them from Host
+	HostOverrides APIHostOverrides
+
+	// GitHub Token the server authenticates with, which is only used for requests that do not
+	// carry their own token in the Authorization header if AllowServerToken is set
+	Token string
+
+	// GitHubApp configures authentication as a GitHub App installation instead of Token
+	GitHubApp GitHubAppConfig
+
+	// AllowServerToken lets requests without an Authorization header act as Token or GitHubApp.
+	// Otherwise every request must be authenticated.
+	AllowServerToken bool
+
+	// EnabledToolsets is a list of toolsets to enable
+	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
+	EnabledToolsets []string
+
+	// Tools, if not empty, limits the tools of the enabled toolsets to those named
+	Tools []string
+
+	// ExcludeTools lists tools of the enabled toolsets that are not offered
+	ExcludeTools []string
+
+	// Whether to enable dynamic toolsets
+	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#dynamic-tool-discovery
+	DynamicToolsets bool
+
+	// ReadOnly indicates if we should only register read-only tools
+	ReadOnly bool
+
+	// DryRun reports the changes write tools would make instead of making them
+	DryRun bool
+
+	// ExportTranslations indicates if we should export translations
+	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#i18n--overriding-descriptions
+	ExportTranslations bool
+
+	// Descriptions overrides tool descriptions by translation key, taking precedence over the
+	// translations file but not over GITHUB_MCP_ env vars
+	Descriptions map[string]string
+
+	// Path to the log file if not stderr
+	LogFilePath string
+
+	// RateLimit configures how requests rejected by GitHub's rate limits are retried
+	RateLimit RateLimitConfig
+
+	// Cache configures the conditional request cache for REST API reads
+	Cache CacheConfig
+
+	// MetricsAddress, if set, is the address to serve Prometheus metrics on at /metrics
+	MetricsAddress string
+
+	// Tracing configures exporting OpenTelemetry traces
+	Tracing TracingConfig
+
+	// Audit configures the audit log of write tool calls
+	Audit AuditConfig
+
+	// PolicyPath, if set, is a JSON file restricting the repositories tools and resources can access
+	PolicyPath string
+
+	// Reload configures reloading the configuration while the server runs
+	Reload ReloadConfig
+
+	// Hosts are additional GitHub hosts that tools can be called against with their host argument
+	Hosts []HostConfig
+
+	// Network configures the certificate authorities, client certificates and proxy used to connect to GitHub
+	Network NetworkConfig
+
+	// Budget limits the size of tool results, which are truncated with continuation tokens for the rest
+	Budget budget.Config
+
+	// OutputFormat is the format JSON tool results are rendered in unless a call asks for another one,
+	// defaults to compact JSON
+	OutputFormat github.OutputFormat
+
+	// Address to listen on for HTTP requests (e.g. "localhost:8080", or ":8080" for every interface)
+	Address string
+
+	// ShutdownTimeout is how long in-flight requests are given to complete on shutdown
+	ShutdownTimeout time.Duration
+}
+
+// RunHTTPServer serves the MCP streamable HTTP transport on cfg.Address until
+// an interrupt or termination signal is received.
+func RunHTTPServer(cfg HTTPServerConfig) error {
+	serverCredentials := cfg.Token != "" || cfg.GitHubApp.Enabled()
+	if cfg.AllowServerToken && !serverCredentials {
+		return fmt.Errorf("requests without a token cannot act as the server, as it has no token or GitHub App")
+	}
+
+	// Create app context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	t, dumpTranslations := translations.TranslationHelperWithOverrides(cfg.Descriptions)
+
+	logrusLogger, err := newLogger(cfg.LogFilePath)
+	if err != nil {
+		return err
+	}
+
+	var serverMetrics *metrics.Metrics
+	if cfg.MetricsAddress != "" {
+		serverMetrics = metrics.New()
+		metricsServer, err := startMetricsServer(cfg.MetricsAddress, serverMetrics, logrusLogger)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = metricsServer.Close() }()
+	}
+
+	tracer, stopTracing, err := startTracing(ctx, cfg.Tracing, cfg.Version)
+	if err != nil {
+		return err
+	}
+	defer stopTracing()
+
+	auditor, stopAudit, err := startAudit(cfg.Audit)
+	if err != nil {
+		return err
+	}
+	defer stopAudit()
+
+	var repoPolicy *policy.Policy
+	if cfg.PolicyPath != "" {
+		repoPolicy, err = policy.Load(cfg.PolicyPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	ghServer, serverReloader, err := newMCPServer(MCPServerConfig{
+		Version:         cfg.Version,
+		Host:            cfg.Host,
+		HostOverrides:   cfg.HostOverrides,
+		Token:           cfg.Token,
+		GitHubApp:       cfg.GitHubApp,
+		EnabledToolsets: cfg.EnabledToolsets,
+		Tools:           cfg.Tools,
+		ExcludeTools:    cfg.ExcludeTools,
+		DynamicToolsets: cfg.DynamicToolsets,
+		ReadOnly:        cfg.ReadOnly,
+		DryRun:          cfg.DryRun,
+		Translator:      t,
+		RateLimit:       cfg.RateLimit,
+		Cache:           cfg.Cache,
+		Metrics:         serverMetrics,
+		Tracer:          tracer,
+		Auditor:         auditor,
+		Policy:          repoPolicy,
+		Logger:          logrusLogger,
+		Hosts:           cfg.Hosts,
+		Network:         cfg.Network,
+		Budget:          cfg.Budget,
+		OutputFormat:    cfg.OutputFormat,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create MCP server: %w", err)
+	}
+
+	if cfg.Reload.Load != nil {
+		go serverReloader.watch(ctx, cfg.Reload, cfg.PolicyPath, logrusLogger)
+	}
+
+	httpServer := &http.Server{
+		Addr:              cfg.Address,
+		Handler:           newHTTPHandler(ghServer, cfg, logrusLogger),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if cfg.ExportTranslations {
+		// Once server is initialized, all translations are loaded
+		dumpTranslations()
+	}
+
+	// Start listening for requests
+	errC := make(chan error, 1)
+	go func() {
+		errC <- httpServer.ListenAndServe()
+	}()
+
+	// Output github-mcp-server string
+	_, _ = fmt.Fprintf(os.Stderr, "GitHub MCP Server running on http://%s/mcp\n", cfg.Address)
+
+	// Wait for shutdown signal
+	select {
+	case <-ctx.Done():
+		logrusLogger.Infof("shutting down server...")
+
+		shutdownTimeout := cfg.ShutdownTimeout
+		if shutdownTimeout == 0 {
+			shutdownTimeout = 10 * time.Second
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("error shutting down server: %w", err)
+		}
+	case err := <-errC:
+		if err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("error running server: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newHTTPHandler serves the streamable HTTP transport of ghServer at /mcp. Tool calls act with the
+// tokens sent in the headers of their request.
+func newHTTPHandler(ghServer *server.MCPServer, cfg HTTPServerConfig, logger *logrus.Logger) http.Handler {
+	mcpHandler := server.NewStreamableHTTPServer(ghServer,
+		server.WithLogger(logger),
+		server.WithHTTPContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+			if token, ok := parseAuthorizationHeader(r.Header.Get("Authorization")); ok {
+				ctx = contextWithToken(ctx, token)
+			}
+			ctx = contextWithHostTokens(ctx, hostTokensFromHeader(r.Header, cfg.Hosts))
+			// enable GitHub errors in the context of every request
+			return errors.ContextWithGitHubErrors(ctx)
+		}),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", requireAuthorization(mcpHandler, !cfg.AllowServerToken))
+	return mux
+}
+
+// startMetricsServer serves m at /metrics on address in the background. Failing to listen is
+// reported right away, while later errors are only logged as metrics are not essential.
+func startMetricsServer(address string, m *metrics.Metrics, logger *logrus.Logger) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	metricsServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := metricsServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("metrics server stopped: %v", err)
+		}
+	}()
+
+	logger.Infof("serving metrics on http://%s/metrics", listener.Addr())
+	return metricsServer, nil
+}
+
+// requireAuthorization rejects requests without a usable Authorization header when required is set,
+// as there is no server token to fall back to.
+func requireAuthorization(next http.Handler, required bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := parseAuthorizationHeader(r.Header.Get("Authorization")); required && !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="github-mcp-server"`)
+			http.Error(w, "missing or malformed Authorization header", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newLogger creates the server logger, writing to the log file at path if one is provided.
+func newLogger(path string) (*logrus.Logger, error) {
+	logrusLogger := logrus.New()
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+
+		logrusLogger.SetLevel(logrus.DebugLevel)
+		logrusLogger.SetOutput(file)
+	}
+	return logrusLogger, nil
+}
+
 type apiHost struct {
 	baseRESTURL *url.URL
 	graphqlURL  *url.URL
 	uploadURL   *url.URL
 	rawURL      *url.URL
+	// webURL is the root of the web UI, which also serves the OAuth endpoints
+	webURL *url.URL
+	// ghes is set for GitHub Enterprise Server, whose release decides which tools are available
+	ghes bool
 }
 
 func newDotcomHost() (apiHost, error) {
@@ -279,11 +1113,17 @@ func newDotcomHost() (apiHost, error) {
 		return apiHost{}, fmt.Errorf("failed to parse dotcom Raw URL: %w", err)
 	}
 
+	webURL, err := url.Parse("https://github.com/")
+	if err != nil {
+		return apiHost{}, fmt.Errorf("failed to parse dotcom Web URL: %w", err)
+	}
+
 	return apiHost{
 		baseRESTURL: baseRestURL,
 		graphqlURL:  gqlURL,
 		uploadURL:   uploadURL,
 		rawURL:      rawURL,
+		webURL:      webURL,
 	}, nil
 }
 
@@ -318,11 +1158,17 @@ func newGHECHost(hostname string) (apiHost, error) {
 		return apiHost{}, fmt.Errorf("failed to parse GHEC Raw URL: %w", err)
 	}
 
+	webURL, err := url.Parse(fmt.Sprintf("https://%s/", u.Hostname()))
+	if err != nil {
+		return apiHost{}, fmt.Errorf("failed to parse GHEC Web URL: %w", err)
+	}
+
 	return apiHost{
 		baseRESTURL: restURL,
 		graphqlURL:  gqlURL,
 		uploadURL:   uploadURL,
 		rawURL:      rawURL,
+		webURL:      webURL,
 	}, nil
 }
 
@@ -332,34 +1178,42 @@ func newGHESHost(hostname string) (apiHost, error) {
 		return apiHost{}, fmt.Errorf("failed to parse GHES URL: %w", err)
 	}
 
-	restURL, err := url.Parse(fmt.Sprintf("%s://%s/api/v3/", u.Scheme, u.Hostname()))
+	restURL, err := url.Parse(fmt.Sprintf("%s://%s/api/v3/", u.Scheme, u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES REST URL: %w", err)
 	}
 
-	gqlURL, err := url.Parse(fmt.Sprintf("%s://%s/api/graphql", u.Scheme, u.Hostname()))
+	gqlURL, err := url.Parse(fmt.Sprintf("%s://%s/api/graphql", u.Scheme, u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES GraphQL URL: %w", err)
 	}
 
-	uploadURL, err := url.Parse(fmt.Sprintf("%s://%s/api/uploads/", u.Scheme, u.Hostname()))
+	uploadURL, err := url.Parse(fmt.Sprintf("%s://%s/api/uploads/", u.Scheme, u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES Upload URL: %w", err)
 	}
-	rawURL, err := url.Parse(fmt.Sprintf("%s://%s/raw/", u.Scheme, u.Hostname()))
+	rawURL, err := url.Parse(fmt.Sprintf("%s://%s/raw/", u.Scheme, u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES Raw URL: %w", err)
 	}
 
+	webURL, err := url.Parse(fmt.Sprintf("%s://%s/", u.Scheme, u.Host))
+	if err != nil {
+		return apiHost{}, fmt.Errorf("failed to parse GHES Web URL: %w", err)
+	}
+
 	return apiHost{
 		baseRESTURL: restURL,
 		graphqlURL:  gqlURL,
 		uploadURL:   uploadURL,
 		rawURL:      rawURL,
+		webURL:      webURL,
+		ghes:        true,
 	}, nil
 }
 
-// Note that this does not handle ports yet, so development environments are out.
+// parseAPIHost derives the API URLs from the host, any port is preserved for GitHub Enterprise Server
+// so that appliances on non-standard ports and local development servers can be targeted.
 func parseAPIHost(s string) (apiHost, error) {
 	if s == "" {
 		return newDotcomHost()
@@ -370,7 +1224,7 @@ func parseAPIHost(s string) (apiHost, error) {
 		return apiHost{}, fmt.Errorf("could not parse host as URL: %s", s)
 	}
 
-	if u.Scheme == "" {
+	if u.Scheme == "" || u.Host == "" {
 		return apiHost{}, fmt.Errorf("host must have a scheme (http or https): %s", s)
 	}
 
@@ -385,6 +1239,83 @@ func parseAPIHost(s string) (apiHost, error) {
 	return newGHESHost(s)
 }
 
+// APIHostOverrides explicitly sets individual API URLs instead of deriving them from the host,
+// e.g. to target a local fake server. Empty fields keep the derived URL.
+type APIHostOverrides struct {
+	// RESTURL is the base URL of the REST API (e.g. https://api.github.com/)
+	RESTURL string
+
+	// GraphQLURL is the URL of the GraphQL API (e.g. https://api.github.com/graphql)
+	GraphQLURL string
+
+	// UploadURL is the base URL for uploads (e.g. https://uploads.github.com/)
+	UploadURL string
+
+	// RawURL is the base URL of raw file contents (e.g. https://raw.githubusercontent.com/)
+	RawURL string
+}
+
+// withOverrides returns a copy of the host with the non-empty overrides applied.
+func (h apiHost) withOverrides(overrides APIHostOverrides) (apiHost, error) {
+	override := func(name string, raw string, trailingSlash bool, target **url.URL) error {
+		if raw == "" {
+			return nil
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s URL override: %w", name, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s URL override must be absolute with a scheme (http or https): %s", name, raw)
+		}
+		// The base URLs are resolved against by relative paths, so they must end with a slash
+		if trailingSlash && !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		*target = u
+		return nil
+	}
+
+	if err := override("REST", overrides.RESTURL, true, &h.baseRESTURL); err != nil {
+		return apiHost{}, err
+	}
+	if err := override("GraphQL", overrides.GraphQLURL, false, &h.graphqlURL); err != nil {
+		return apiHost{}, err
+	}
+	if err := override("Upload", overrides.UploadURL, true, &h.uploadURL); err != nil {
+		return apiHost{}, err
+	}
+	if err := override("Raw", overrides.RawURL, true, &h.rawURL); err != nil {
+		return apiHost{}, err
+	}
+	return h, nil
+}
+
+// classify labels a request for metrics with the GitHub API it targets and its endpoint template.
+func (h apiHost) classify(req *http.Request) (api, endpoint string) {
+	under := func(base *url.URL) (string, bool) {
+		if base == nil || req.URL.Host != base.Host {
+			return "", false
+		}
+		return strings.CutPrefix(req.URL.Path, strings.TrimSuffix(base.Path, "/"))
+	}
+
+	// GraphQL and raw content may live under the REST base URL on GHES, so they are checked first
+	if req.URL.Host == h.graphqlURL.Host && req.URL.Path == h.graphqlURL.Path {
+		return "graphql", "/graphql"
+	}
+	if _, ok := under(h.rawURL); ok {
+		return "raw", "/{owner}/{repo}/{ref}/{path}"
+	}
+	if path, ok := under(h.uploadURL); ok {
+		return "upload", metrics.RESTEndpoint(path)
+	}
+	if path, ok := under(h.baseRESTURL); ok {
+		return "rest", metrics.RESTEndpoint(path)
+	}
+	return "other", "/"
+}
+
 type userAgentTransport struct {
 	transport http.RoundTripper
 	agent     string
@@ -398,11 +1329,15 @@ func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error
 
 type bearerAuthTransport struct {
 	transport http.RoundTripper
-	token     string
+	tokens    tokenSource
 }
 
 func (t *bearerAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.tokens.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub token: %w", err)
+	}
 	req = req.Clone(req.Context())
-	req.Header.Set("Authorization", "Bearer "+t.token)
+	req.Header.Set("Authorization", "Bearer "+token)
 	return t.transport.RoundTrip(req)
 }
@@ -0,0 +1,282 @@
+package ghmcp
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIHost(t *testing.T) {
+	tests := []struct {
+		name            string
+		host            string
+		expectedREST    string
+		expectedGraphQL string
+		expectedUpload  string
+		expectedRaw     string
+		expectedWeb     string
+		expectError     bool
+	}{
+		{
+			name:            "default is dotcom",
+			host:            "",
+			expectedREST:    "https://api.github.com/",
+			expectedGraphQL: "https://api.github.com/graphql",
+			expectedUpload:  "https://uploads.github.com",
+			expectedRaw:     "https://raw.githubusercontent.com/",
+			expectedWeb:     "https://github.com/",
+		},
+		{
+			name:            "GHEC with data residency",
+			host:            "https://tenant.ghe.com",
+			expectedREST:    "https://api.tenant.ghe.com/",
+			expectedGraphQL: "https://api.tenant.ghe.com/graphql",
+			expectedUpload:  "https://uploads.tenant.ghe.com",
+			expectedRaw:     "https://raw.tenant.ghe.com/",
+			expectedWeb:     "https://tenant.ghe.com/",
+		},
+		{
+			name:            "GHES",
+			host:            "https://github.example.com",
+			expectedREST:    "https://github.example.com/api/v3/",
+			expectedGraphQL: "https://github.example.com/api/graphql",
+			expectedUpload:  "https://github.example.com/api/uploads/",
+			expectedRaw:     "https://github.example.com/raw/",
+			expectedWeb:     "https://github.example.com/",
+		},
+		{
+			name:            "GHES on a non-standard port",
+			host:            "https://github.example.com:8443",
+			expectedREST:    "https://github.example.com:8443/api/v3/",
+			expectedGraphQL: "https://github.example.com:8443/api/graphql",
+			expectedUpload:  "https://github.example.com:8443/api/uploads/",
+			expectedRaw:     "https://github.example.com:8443/raw/",
+			expectedWeb:     "https://github.example.com:8443/",
+		},
+		{
+			name:            "local development server",
+			host:            "http://localhost:8080",
+			expectedREST:    "http://localhost:8080/api/v3/",
+			expectedGraphQL: "http://localhost:8080/api/graphql",
+			expectedUpload:  "http://localhost:8080/api/uploads/",
+			expectedRaw:     "http://localhost:8080/raw/",
+			expectedWeb:     "http://localhost:8080/",
+		},
+		{
+			name:        "missing scheme",
+			host:        "github.example.com",
+			expectError: true,
+		},
+		{
+			name:        "missing scheme with port",
+			host:        "localhost:8443",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host, err := parseAPIHost(tc.host)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedREST, host.baseRESTURL.String())
+			assert.Equal(t, tc.expectedGraphQL, host.graphqlURL.String())
+			assert.Equal(t, tc.expectedUpload, host.uploadURL.String())
+			assert.Equal(t, tc.expectedRaw, host.rawURL.String())
+			assert.Equal(t, tc.expectedWeb, host.webURL.String())
+		})
+	}
+}
+
+func TestAPIHostWithOverrides(t *testing.T) {
+	host, err := parseAPIHost("")
+	require.NoError(t, err)
+
+	overridden, err := host.withOverrides(APIHostOverrides{
+		RESTURL:    "http://localhost:8443/api",
+		GraphQLURL: "http://localhost:8443/graphql",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://localhost:8443/api/", overridden.baseRESTURL.String(), "base URLs should get a trailing slash")
+	assert.Equal(t, "http://localhost:8443/graphql", overridden.graphqlURL.String())
+	assert.Equal(t, host.uploadURL, overridden.uploadURL, "URLs without an override should be kept")
+	assert.Equal(t, host.rawURL, overridden.rawURL, "URLs without an override should be kept")
+	assert.Equal(t, "https://api.github.com/", host.baseRESTURL.String(), "the original host should not be modified")
+
+	_, err = host.withOverrides(APIHostOverrides{RawURL: "/raw/"})
+	assert.Error(t, err, "relative overrides should be rejected")
+}
+
+func TestAPIHostClassify(t *testing.T) {
+	tests := []struct {
+		name             string
+		host             string
+		url              string
+		expectedAPI      string
+		expectedEndpoint string
+	}{
+		{
+			name:             "dotcom REST",
+			url:              "https://api.github.com/repos/octo/hello-world/pulls/7",
+			expectedAPI:      "rest",
+			expectedEndpoint: "/repos/{owner}/{repo}/pulls/{id}",
+		},
+		{
+			name:             "dotcom GraphQL",
+			url:              "https://api.github.com/graphql",
+			expectedAPI:      "graphql",
+			expectedEndpoint: "/graphql",
+		},
+		{
+			name:             "dotcom raw",
+			url:              "https://raw.githubusercontent.com/octo/hello-world/main/README.md",
+			expectedAPI:      "raw",
+			expectedEndpoint: "/{owner}/{repo}/{ref}/{path}",
+		},
+		{
+			name:             "GHES REST",
+			host:             "https://github.example.com",
+			url:              "https://github.example.com/api/v3/user",
+			expectedAPI:      "rest",
+			expectedEndpoint: "/user",
+		},
+		{
+			name:             "GHES GraphQL",
+			host:             "https://github.example.com",
+			url:              "https://github.example.com/api/graphql",
+			expectedAPI:      "graphql",
+			expectedEndpoint: "/graphql",
+		},
+		{
+			name:             "GHES raw",
+			host:             "https://github.example.com",
+			url:              "https://github.example.com/raw/octo/hello-world/main/README.md",
+			expectedAPI:      "raw",
+			expectedEndpoint: "/{owner}/{repo}/{ref}/{path}",
+		},
+		{
+			name:             "another host",
+			url:              "https://example.com/repos/octo/hello-world",
+			expectedAPI:      "other",
+			expectedEndpoint: "/",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host, err := parseAPIHost(tc.host)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			api, endpoint := host.classify(req)
+			assert.Equal(t, tc.expectedAPI, api)
+			assert.Equal(t, tc.expectedEndpoint, endpoint)
+		})
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	var authorization string
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login":"octocat"}`))
+	})
+	ghServer, _, err := newMCPServer(testServerConfig(t, api, MCPServerConfig{
+		EnabledToolsets: []string{"context"},
+	}))
+	require.NoError(t, err)
+
+	initialize := map[string]any{
+		"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+		"clientInfo":      map[string]any{"name": "test", "version": "1.0"},
+	}
+	getMe := map[string]any{"name": "get_me", "arguments": map[string]any{}}
+	bearer := func(token string) http.Header {
+		return http.Header{"Authorization": []string{"Bearer " + token}}
+	}
+
+	t.Run("requests act with their token", func(t *testing.T) {
+		ts := httptest.NewServer(newHTTPHandler(ghServer, HTTPServerConfig{}, logrus.New()))
+		defer ts.Close()
+
+		resp, _ := postMCP(t, ts.URL+"/mcp", bearer("ghp_request"), "", "initialize", initialize)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		sessionID := resp.Header.Get("Mcp-Session-Id")
+		require.NotEmpty(t, sessionID)
+
+		_, message := postMCP(t, ts.URL+"/mcp", bearer("ghp_request"), sessionID, "tools/call", getMe)
+		assert.Contains(t, string(message), "octocat")
+		assert.Equal(t, "Bearer ghp_request", authorization)
+
+		_, _ = postMCP(t, ts.URL+"/mcp", bearer("ghp_other"), sessionID, "tools/call", getMe)
+		assert.Equal(t, "Bearer ghp_other", authorization, "the token of each request should be used, not the first of the session")
+	})
+
+	t.Run("requests without a token are rejected", func(t *testing.T) {
+		ts := httptest.NewServer(newHTTPHandler(ghServer, HTTPServerConfig{}, logrus.New()))
+		defer ts.Close()
+
+		resp, _ := postMCP(t, ts.URL+"/mcp", nil, "", "initialize", initialize)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, `Bearer realm="github-mcp-server"`, resp.Header.Get("WWW-Authenticate"))
+	})
+
+	t.Run("requests without a token act as the server if allowed", func(t *testing.T) {
+		ts := httptest.NewServer(newHTTPHandler(ghServer, HTTPServerConfig{AllowServerToken: true}, logrus.New()))
+		defer ts.Close()
+
+		resp, _ := postMCP(t, ts.URL+"/mcp", nil, "", "initialize", initialize)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, message := postMCP(t, ts.URL+"/mcp", nil, resp.Header.Get("Mcp-Session-Id"), "tools/call", getMe)
+		assert.Contains(t, string(message), "octocat")
+		assert.Equal(t, "Bearer ghp_test", authorization)
+	})
+
+	t.Run("only /mcp is served", func(t *testing.T) {
+		ts := httptest.NewServer(newHTTPHandler(ghServer, HTTPServerConfig{}, logrus.New()))
+		defer ts.Close()
+
+		resp, _ := postMCP(t, ts.URL+"/other", bearer("ghp_request"), "", "initialize", initialize)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestRunHTTPServer(t *testing.T) {
+	t.Run("fails if the address is in use", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		defer func() { _ = listener.Close() }()
+
+		err = RunHTTPServer(HTTPServerConfig{
+			Version: "test",
+			Token:   "ghp_test",
+			Address: listener.Addr().String(),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error running server")
+	})
+
+	t.Run("fails without credentials to act as the server", func(t *testing.T) {
+		err := RunHTTPServer(HTTPServerConfig{
+			Version:          "test",
+			AllowServerToken: true,
+			Address:          "localhost:0",
+		})
+		assert.ErrorContains(t, err, "requests without a token cannot act as the server")
+	})
+}
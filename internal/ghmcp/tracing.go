@@ -0,0 +1,83 @@
+package ghmcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/tracing"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// TracingConfig configures exporting OpenTelemetry traces over OTLP/HTTP.
+type TracingConfig struct {
+	// Endpoint is the OTLP/HTTP endpoint URL to export spans to (e.g. http://localhost:4318). If empty,
+	// tracing is still enabled when the standard OTEL_EXPORTER_OTLP_ENDPOINT environment variables are set.
+	Endpoint string
+}
+
+// Enabled reports whether spans should be exported.
+func (c TracingConfig) Enabled() bool {
+	return c.Endpoint != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// newTracerProvider creates a provider that batches spans to the OTLP endpoint, or returns nil if
+// tracing is disabled. The remaining exporter settings, such as headers, are read from the standard
+// OTEL_EXPORTER_OTLP_* environment variables.
+func (c TracingConfig) newTracerProvider(ctx context.Context, version string) (*sdktrace.TracerProvider, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if c.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(c.Endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence over our defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName("github-mcp-server"),
+			semconv.ServiceVersion(version),
+		),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+// startTracing creates the tracer of a server, returning a function that flushes pending spans
+// on shutdown. The tracer is nil if tracing is disabled.
+func startTracing(ctx context.Context, cfg TracingConfig, version string) (*tracing.Tracer, func(), error) {
+	provider, err := cfg.newTracerProvider(ctx, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if provider == nil {
+		return nil, func() {}, nil
+	}
+
+	shutdown := func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = provider.Shutdown(shutdownCtx)
+	}
+	return tracing.New(provider), shutdown, nil
+}
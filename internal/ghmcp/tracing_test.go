@@ -0,0 +1,65 @@
+package ghmcp
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewMCPServer_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	ghServer := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/user", r.URL.Path)
+		w.Header().Set("X-GitHub-Request-Id", "ABCD:1234")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login":"octocat"}`))
+	}), MCPServerConfig{
+		EnabledToolsets: []string{"context"},
+		Tracer:          tracing.New(provider),
+	})
+
+	callTool(t, ghServer, "get_me", map[string]any{})
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	apiSpan, toolSpan := spans[0], spans[1]
+
+	assert.Equal(t, "tools/call get_me", toolSpan.Name)
+	assert.Equal(t, "GET /user", apiSpan.Name)
+	assert.Equal(t, toolSpan.SpanContext.SpanID(), apiSpan.Parent.SpanID())
+	assert.Contains(t, apiSpan.Attributes, tracing.GitHubRequestIDKey.String("ABCD:1234"))
+	assert.Contains(t, apiSpan.Attributes, tracing.GitHubAPIKey.String("rest"))
+}
+
+func TestNewMCPServer_TracingResources(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	ghServer := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.URL.Path, "/raw/octo/hello-world/"), r.URL.Path)
+		w.Header().Set("Content-Type", "text/markdown")
+		_, _ = w.Write([]byte("# Hello"))
+	}), MCPServerConfig{
+		EnabledToolsets: []string{"repos"},
+		Tracer:          tracing.New(provider),
+	})
+
+	sendResult(t, ghServer, "resources/read", map[string]any{"uri": "repo://octo/hello-world/contents/README.md"})
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	apiSpan, readSpan := spans[0], spans[1]
+
+	assert.Equal(t, "resources/read", readSpan.Name)
+	assert.Contains(t, readSpan.Attributes, tracing.MCPResourceURIKey.String("repo://octo/hello-world/contents/README.md"))
+	assert.Equal(t, readSpan.SpanContext.SpanID(), apiSpan.Parent.SpanID(), "API requests should be children of the read")
+	assert.Contains(t, apiSpan.Attributes, tracing.GitHubAPIKey.String("raw"))
+}
@@ -0,0 +1,47 @@
+package ghmcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	gogithub "github.com/google/go-github/v73/github"
+)
+
+// ghesVersionTimeout bounds looking up the release of a GitHub Enterprise Server instance at startup.
+const ghesVersionTimeout = 10 * time.Second
+
+// detectVersion returns the release of GitHub Enterprise Server the host runs, which is empty for
+// github.com and ghe.com.
+func (h *serverHost) detectVersion(ctx context.Context) (string, error) {
+	if !h.api.ghes {
+		return "", nil
+	}
+
+	// The meta endpoint does not need authentication unless the instance is in private mode
+	var client *gogithub.Client
+	if h.tokens != nil {
+		client = h.clients.get(serverCredentialsKey, h.tokens).rest
+	} else {
+		client = h.clients.anonymousREST()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ghesVersionTimeout)
+	defer cancel()
+
+	req, err := client.NewRequest(http.MethodGet, "meta", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create meta request: %w", err)
+	}
+	var meta struct {
+		InstalledVersion string `json:"installed_version"`
+	}
+	if _, err := client.Do(ctx, req, &meta); err != nil {
+		return "", fmt.Errorf("failed to get meta information: %w", err)
+	}
+	if meta.InstalledVersion == "" {
+		return "", fmt.Errorf("meta information has no installed version")
+	}
+	return meta.InstalledVersion, nil
+}
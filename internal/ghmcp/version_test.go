@@ -0,0 +1,104 @@
+package ghmcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMCPServer_GHESVersion(t *testing.T) {
+	// ghesServer is a GitHub Enterprise Server instance running the release
+	ghesServer := func(version string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v3/meta" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"verifiable_password_authentication":true,"installed_version":"` + version + `"}`))
+		}))
+	}
+	old := ghesServer("3.12.4")
+	defer old.Close()
+	current := ghesServer("3.17.0")
+	defer current.Close()
+
+	// Without additional hosts, the tools and arguments the release does not have are hidden
+	ghServer := newTestServer(t, nil, MCPServerConfig{
+		Host:            old.URL,
+		EnabledToolsets: []string{"issues", "discussions"},
+	})
+	tools := listTools(t, ghServer)
+	assert.NotContains(t, tools, "add_sub_issue")
+	assert.NotContains(t, tools, "assign_copilot_to_issue")
+	require.Contains(t, tools, "list_discussions")
+	assert.NotContains(t, tools["list_discussions"].InputSchema.Properties, "orderBy")
+
+	// With them, a tool is listed for the hosts that have it
+	ghServer = newTestServer(t, nil, MCPServerConfig{
+		Host:            old.URL,
+		EnabledToolsets: []string{"issues", "discussions"},
+		Hosts: []HostConfig{
+			{Name: "current", Host: current.URL, Token: "ghp_test"},
+			{Name: "dotcom", Host: "https://github.com", Token: "ghp_test"},
+		},
+	})
+	tools = listTools(t, ghServer)
+	hostsOf := func(tool string) any {
+		require.Contains(t, tools, tool)
+		return tools[tool].InputSchema.Properties["host"].(map[string]any)["enum"]
+	}
+	assert.Equal(t, []string{"default", "current", "dotcom"}, hostsOf("get_issue"))
+	assert.Equal(t, []string{"current", "dotcom"}, hostsOf("add_sub_issue"))
+	assert.Equal(t, []string{"dotcom"}, hostsOf("assign_copilot_to_issue"))
+	assert.Contains(t, tools["list_discussions"].InputSchema.Properties, "orderBy")
+
+	// Calls are rejected on the hosts whose release does not have the tool or argument
+	subIssue := map[string]any{"owner": "octo", "repo": "hello-world", "issue_number": 1, "sub_issue_id": 2}
+	result := callTool(t, ghServer, "add_sub_issue", subIssue)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "tool add_sub_issue requires GitHub Enterprise Server 3.17 or later, the server runs 3.12.4 on host default", toolText(t, result))
+
+	// The host with the release reaches the API, which the test instance only answers for meta
+	subIssue["host"] = "current"
+	result = callTool(t, ghServer, "add_sub_issue", subIssue)
+	assert.NotContains(t, toolText(t, result), "requires GitHub Enterprise Server")
+	assert.Contains(t, toolText(t, result), "404")
+
+	result = callTool(t, ghServer, "list_discussions", map[string]any{"owner": "octo", "repo": "hello-world", "orderBy": "CREATED_AT", "direction": "DESC"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, toolText(t, result), "requires GitHub Enterprise Server 3.13 or later, the server runs 3.12.4 on host default")
+
+	result = callTool(t, ghServer, "assign_copilot_to_issue", map[string]any{"host": "current", "owner": "octo", "repo": "hello-world", "issueNumber": 1})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "tool assign_copilot_to_issue is not available on GitHub Enterprise Server on host current", toolText(t, result))
+}
+
+func Test_DetectVersion(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/meta", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"), "no credentials to send")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"installed_version":"3.14.7"}`))
+	}))
+	defer ts.Close()
+
+	api, err := parseAPIHost(ts.URL)
+	require.NoError(t, err)
+	host := &serverHost{api: api, clients: newClientCache(api, clientTransports{rest: http.DefaultTransport}, "test")}
+	version, err := host.detectVersion(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "3.14.7", version)
+
+	// Other hosts are not GitHub Enterprise Server
+	dotcom, err := parseAPIHost("https://github.com")
+	require.NoError(t, err)
+	host = &serverHost{api: dotcom}
+	version, err = host.detectVersion(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, version)
+}
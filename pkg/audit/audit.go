@@ -0,0 +1,186 @@
+// Package audit records tool calls that write to GitHub as JSON lines, for compliance purposes.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Outcomes of an audited tool call.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// maxSessions bounds the client info remembered per session, as sessions of the streamable HTTP
+// transport are never unregistered.
+const maxSessions = 1024
+
+// Client identifies the MCP client that made a tool call, as reported in its initialize request.
+type Client struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Event is a single line of the audit log.
+type Event struct {
+	Time      time.Time      `json:"time"`
+	SessionID string         `json:"session_id,omitempty"`
+	Client    *Client        `json:"client,omitempty"`
+	Login     string         `json:"login,omitempty"`
+	Tool      string         `json:"tool"`
+	Owner     string         `json:"owner,omitempty"`
+	Repo      string         `json:"repo,omitempty"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	Outcome   string         `json:"outcome"`
+	Error     string         `json:"error,omitempty"`
+	// DryRun is set for calls that only reported the changes they would make, without making them
+	DryRun bool `json:"dry_run,omitempty"`
+	// DurationMS is how long the tool call took in milliseconds
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// Auditor writes an Event for every audited tool call.
+type Auditor struct {
+	redactor *Redactor
+	now      func() time.Time
+
+	mu sync.Mutex
+	w  io.Writer
+	// clients holds the client of each session, with sessions in the order they initialized
+	clients  map[string]Client
+	sessions []string
+}
+
+// New creates an Auditor that writes JSON lines to w, with arguments redacted by redactor.
+func New(w io.Writer, redactor *Redactor) *Auditor {
+	return &Auditor{
+		redactor: redactor,
+		now:      time.Now,
+		w:        w,
+		clients:  make(map[string]Client),
+	}
+}
+
+// AddHooks remembers the client of every session from its initialize request.
+func (a *Auditor) AddHooks(hooks *server.Hooks) {
+	hooks.AddAfterInitialize(func(ctx context.Context, _ any, message *mcp.InitializeRequest, _ *mcp.InitializeResult) {
+		sessionID := sessionID(ctx)
+		if sessionID == "" {
+			return
+		}
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		if _, ok := a.clients[sessionID]; !ok {
+			a.sessions = append(a.sessions, sessionID)
+		}
+		a.clients[sessionID] = Client{
+			Name:    message.Params.ClientInfo.Name,
+			Version: message.Params.ClientInfo.Version,
+		}
+		for len(a.sessions) > maxSessions {
+			delete(a.clients, a.sessions[0])
+			a.sessions = a.sessions[1:]
+		}
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		delete(a.clients, session.SessionID())
+		for i, id := range a.sessions {
+			if id == session.SessionID() {
+				a.sessions = append(a.sessions[:i], a.sessions[i+1:]...)
+				break
+			}
+		}
+	})
+}
+
+// ToolMiddleware returns a middleware that logs the calls of the tools for which isWrite returns
+// true, once they complete. login returns the GitHub login the call was made as, if known. dryRun
+// marks the events of a server in dry-run mode, whose calls did not change anything on GitHub.
+func (a *Auditor) ToolMiddleware(isWrite func(tool string) bool, login func(ctx context.Context) string, dryRun bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !isWrite(request.Params.Name) {
+				return next(ctx, request)
+			}
+
+			start := a.now()
+			result, err := next(ctx, request)
+
+			arguments := request.GetArguments()
+			event := Event{
+				Time:       start.UTC(),
+				SessionID:  sessionID(ctx),
+				Login:      login(ctx),
+				Tool:       request.Params.Name,
+				Arguments:  a.redactor.Redact(request.Params.Name, arguments),
+				Outcome:    OutcomeSuccess,
+				DryRun:     dryRun,
+				DurationMS: a.now().Sub(start).Milliseconds(),
+			}
+			event.Owner, _ = arguments["owner"].(string)
+			event.Repo, _ = arguments["repo"].(string)
+			switch {
+			case err != nil:
+				event.Outcome = OutcomeError
+				event.Error = err.Error()
+			case result != nil && result.IsError:
+				event.Outcome = OutcomeError
+				event.Error = resultText(result)
+			}
+			if event.Error != "" {
+				event.Error = tokenPattern.ReplaceAllString(event.Error, Redacted)
+			}
+
+			a.log(event)
+			return result, err
+		}
+	}
+}
+
+// log writes event as a single line. Failing to write the audit log does not fail the tool call,
+// which has already been made.
+func (a *Auditor) log(event Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if client, ok := a.clients[event.SessionID]; ok {
+		event.Client = &client
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	_, _ = a.w.Write(append(line, '\n'))
+}
+
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// resultText joins the text content of a tool result, which holds the error message of a failed call.
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
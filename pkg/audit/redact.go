@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the values of redacted arguments.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactions are the arguments that hold free-form content, such as file contents and
+// issue bodies, which is redacted unless configured otherwise.
+var DefaultRedactions = []string{"body", "content", "files", "comments"}
+
+// tokenPattern matches GitHub tokens, which are redacted from every value regardless of the rules.
+var tokenPattern = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})\b`)
+
+// Redactor removes sensitive values from tool arguments before they are logged.
+type Redactor struct {
+	// rules maps a tool name, or "*" for every tool, to the argument names to redact
+	rules map[string]map[string]bool
+}
+
+// NewRedactor parses redaction rules. Each rule is an argument name, like "body", which is redacted
+// for every tool, or a tool name and an argument name separated by a colon, like
+// "create_or_update_file:content". An argument name of "*" redacts every argument. Arguments are
+// matched at any depth, so "content" also redacts the content of every file passed to push_files.
+func NewRedactor(rules []string) (*Redactor, error) {
+	r := &Redactor{rules: make(map[string]map[string]bool)}
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		tool, argument := "*", rule
+		if before, after, found := strings.Cut(rule, ":"); found {
+			tool, argument = strings.TrimSpace(before), strings.TrimSpace(after)
+		}
+		if tool == "" || argument == "" {
+			return nil, fmt.Errorf("invalid redaction rule %q, expected argument or tool:argument", rule)
+		}
+
+		if r.rules[tool] == nil {
+			r.rules[tool] = make(map[string]bool)
+		}
+		r.rules[tool][argument] = true
+	}
+	return r, nil
+}
+
+// Redact returns a copy of the arguments of a call to tool with the values matched by the rules,
+// and any GitHub tokens, replaced.
+func (r *Redactor) Redact(tool string, arguments map[string]any) map[string]any {
+	if arguments == nil {
+		return nil
+	}
+	redacted, _ := r.redactValue(tool, arguments).(map[string]any)
+	return redacted
+}
+
+func (r *Redactor) redacts(tool, argument string) bool {
+	for _, scope := range []string{"*", tool} {
+		if r.rules[scope]["*"] || r.rules[scope][argument] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) redactValue(tool string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, item := range v {
+			if r.redacts(tool, key) {
+				redacted[key] = Redacted
+				continue
+			}
+			redacted[key] = r.redactValue(tool, item)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(tool, item)
+		}
+		return redacted
+	case string:
+		return tokenPattern.ReplaceAllString(v, Redacted)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is a writer that appends to a file, renaming it to path.1, path.2 and so on once it
+// reaches its maximum size. Writes are never split across files.
+type RotatingFile struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile opens the file at path for appending, keeping at most maxBackups rotated files.
+// A maxBytes of zero disables rotation.
+func OpenRotatingFile(path string, maxBytes int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxBytes > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups by one, dropping the oldest, and starts a new file.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+
+	if f.maxBackups > 0 {
+		_ = os.Remove(f.backup(f.maxBackups))
+		for i := f.maxBackups - 1; i >= 1; i-- {
+			_ = os.Rename(f.backup(i), f.backup(i+1))
+		}
+		if err := os.Rename(f.path, f.backup(1)); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	} else if err := os.Remove(f.path); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	return f.open()
+}
+
+func (f *RotatingFile) backup(i int) string {
+	return fmt.Sprintf("%s.%d", f.path, i)
+}
+
+// Close closes the current file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
@@ -0,0 +1,365 @@
+// Package budget limits the size of tool results, cutting large results into parts that are
+// fetched one after the other with continuation tokens.
+package budget
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ContinuationArgument is the tool argument that asks for the next part of a truncated result.
+const ContinuationArgument = "continuation_token"
+
+// Unit is what sizes are measured in.
+type Unit string
+
+const (
+	// Bytes measures sizes in bytes of text
+	Bytes Unit = "bytes"
+	// Tokens measures sizes in approximate model tokens, counted as four bytes each
+	Tokens Unit = "tokens"
+)
+
+// bytesPerToken is the rough number of bytes of JSON or code per model token.
+const bytesPerToken = 4
+
+// ParseUnit returns the unit named s.
+func ParseUnit(s string) (Unit, error) {
+	switch unit := Unit(s); unit {
+	case Bytes, Tokens:
+		return unit, nil
+	default:
+		return "", fmt.Errorf("unknown size unit %q, must be %q or %q", s, Bytes, Tokens)
+	}
+}
+
+// Config configures the size budget of tool results.
+type Config struct {
+	// MaxSize is the size results are cut to, zero disables truncation
+	MaxSize int
+
+	// ToolMaxSizes overrides MaxSize for the named tools, zero disables truncation for a tool
+	ToolMaxSizes map[string]int
+
+	// Unit of the sizes, defaults to Bytes
+	Unit Unit
+
+	// TTL is how long the rest of a truncated result is kept for continuation tokens, defaults to ten minutes
+	TTL time.Duration
+
+	// MaxPending is the number of truncated results kept, the oldest are dropped first, defaults to 100
+	MaxPending int
+}
+
+// Enabled reports whether the results of any tool are truncated.
+func (c Config) Enabled() bool {
+	if c.MaxSize > 0 {
+		return true
+	}
+	for _, size := range c.ToolMaxSizes {
+		if size > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// pending is the rest of a truncated result, waiting to be fetched with a continuation token.
+type pending struct {
+	tool    string
+	session string
+	// arguments are those of the call whose result was truncated
+	arguments map[string]any
+	// content is the truncated content, of which text is the part from offset on
+	content mcp.Content
+	text    string
+	offset  int
+	expires time.Time
+}
+
+// Budget truncates tool results to their size budget and keeps the rest for continuation calls.
+type Budget struct {
+	cfg Config
+	now func() time.Time
+
+	// check is applied to continuation calls, see CheckContinuations
+	check server.ToolHandlerMiddleware
+
+	mu      sync.Mutex
+	pending map[string]*pending
+	// order holds the continuation tokens from oldest to newest
+	order []string
+}
+
+// New returns a budget for the tool results of a server.
+func New(cfg Config) *Budget {
+	if cfg.Unit == "" {
+		cfg.Unit = Bytes
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 10 * time.Minute
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 100
+	}
+	return &Budget{
+		cfg:     cfg,
+		now:     time.Now,
+		pending: make(map[string]*pending),
+	}
+}
+
+// CheckContinuations applies check to continuation calls, with the arguments of the call whose
+// result was truncated. The middleware after the budget is not called for continuations, so the
+// checks it makes, such as a repository policy that has since changed, are made again with check.
+func (b *Budget) CheckContinuations(check server.ToolHandlerMiddleware) {
+	b.check = check
+}
+
+// maxBytes returns the budget of the tool in bytes, zero if its results are not truncated.
+func (b *Budget) maxBytes(tool string) int {
+	size, ok := b.cfg.ToolMaxSizes[tool]
+	if !ok {
+		size = b.cfg.MaxSize
+	}
+	if b.cfg.Unit == Tokens {
+		return size * bytesPerToken
+	}
+	return size
+}
+
+// ToolMiddleware truncates the results of tools that exceed their budget, and answers calls with
+// a continuation token with the next part of the result without calling the tool again.
+func (b *Budget) ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tool := request.Params.Name
+		if arg, ok := request.GetArguments()[ContinuationArgument]; ok && arg != "" {
+			token, ok := arg.(string)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("parameter %s is not of type string, is %T", ContinuationArgument, arg)), nil
+			}
+			p, errResult := b.lookup(ctx, tool, token)
+			if errResult != nil {
+				return errResult, nil
+			}
+			if b.check == nil {
+				return b.continueResult(ctx, p), nil
+			}
+			request.Params.Arguments = p.arguments
+			return b.check(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return b.continueResult(ctx, p), nil
+			})(ctx, request)
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		maxBytes := b.maxBytes(tool)
+		if maxBytes <= 0 {
+			return result, nil
+		}
+		return b.truncate(ctx, tool, request.GetArguments(), result, maxBytes), nil
+	}
+}
+
+// ToolFilter adds the continuation token argument to the listed tools whose results are truncated.
+func (b *Budget) ToolFilter(_ context.Context, tools []mcp.Tool) []mcp.Tool {
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if b.maxBytes(tool.Name) > 0 {
+			tool = github.WithToolProperty(tool, ContinuationArgument, map[string]any{
+				"type":        "string",
+				"description": "Token from a truncated result of this tool, to get the next part of that result instead of calling the tool again. The other arguments are ignored.",
+			})
+		}
+		filtered = append(filtered, tool)
+	}
+	return filtered
+}
+
+// truncate cuts the largest text of the result so that the result fits maxBytes. The rest of the
+// text is kept for continuation calls.
+func (b *Budget) truncate(ctx context.Context, tool string, arguments map[string]any, result *mcp.CallToolResult, maxBytes int) *mcp.CallToolResult {
+	total, largest := 0, -1
+	for i, content := range result.Content {
+		text, ok := contentText(content)
+		if !ok {
+			continue
+		}
+		total += len(text)
+		if largest < 0 || len(text) > len(mustText(result.Content[largest])) {
+			largest = i
+		}
+	}
+	if total <= maxBytes || largest < 0 {
+		return result
+	}
+
+	text := mustText(result.Content[largest])
+	// The other contents, such as the message of an embedded resource, are kept whole
+	available := maxBytes - (total - len(text))
+	if available < maxBytes/2 {
+		available = maxBytes / 2
+	}
+
+	content := make([]mcp.Content, len(result.Content), len(result.Content)+1)
+	copy(content, result.Content)
+	part := cut(text, available)
+	content[largest] = withText(result.Content[largest], part)
+	content = append(content, mcp.NewTextContent(b.note(ctx, tool, arguments, result.Content[largest], text, 0, len(part))))
+	return &mcp.CallToolResult{Result: result.Result, Content: content}
+}
+
+// lookup returns the truncated result of a continuation token, or an error result if the token
+// cannot be used for a call of the tool in this session.
+func (b *Budget) lookup(ctx context.Context, tool, token string) (*pending, *mcp.CallToolResult) {
+	b.mu.Lock()
+	p, ok := b.pending[token]
+	b.mu.Unlock()
+	if !ok || b.now().After(p.expires) {
+		return nil, mcp.NewToolResultError("continuation token is unknown or has expired, call the tool again without it")
+	}
+	if p.tool != tool || p.session != sessionID(ctx) {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("continuation token does not belong to a result of %s in this session", tool))
+	}
+	return p, nil
+}
+
+// continueResult returns the next part of a truncated result.
+func (b *Budget) continueResult(ctx context.Context, p *pending) *mcp.CallToolResult {
+	tool := p.tool
+	maxBytes := b.maxBytes(tool)
+	if maxBytes <= 0 {
+		// The budget of the tool was lifted, so the whole rest is returned
+		maxBytes = len(p.text) - p.offset
+	}
+	part := cut(p.text[p.offset:], maxBytes)
+	end := p.offset + len(part)
+	return &mcp.CallToolResult{Content: []mcp.Content{
+		withText(p.content, part),
+		mcp.NewTextContent(b.note(ctx, tool, p.arguments, p.content, p.text, p.offset, end)),
+	}}
+}
+
+// note describes the part from start to end of text, and stores the rest under a new continuation
+// token if there is one.
+func (b *Budget) note(ctx context.Context, tool string, arguments map[string]any, content mcp.Content, text string, start, end int) string {
+	if end >= len(text) {
+		return fmt.Sprintf("This is the last part of a truncated result, bytes %d to %d of %d.", start, end, len(text))
+	}
+
+	token := b.store(&pending{
+		tool:      tool,
+		session:   sessionID(ctx),
+		arguments: arguments,
+		content:   content,
+		text:      text,
+		offset:    end,
+		expires:   b.now().Add(b.cfg.TTL),
+	})
+	return fmt.Sprintf("Result truncated to fit the output budget: this is bytes %d to %d of %d, %d bytes were cut. "+
+		"To get the next part, call %s again with %s %q.", start, end, len(text), len(text)-end, tool, ContinuationArgument, token)
+}
+
+// store keeps the rest of a result and returns its continuation token.
+func (b *Budget) store(p *pending) string {
+	raw := make([]byte, 16)
+	_, _ = rand.Read(raw)
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Drop expired results, and the oldest ones beyond the limit
+	now := b.now()
+	kept := b.order[:0]
+	for _, t := range b.order {
+		if now.After(b.pending[t].expires) {
+			delete(b.pending, t)
+			continue
+		}
+		kept = append(kept, t)
+	}
+	b.order = kept
+	for len(b.order) >= b.cfg.MaxPending {
+		delete(b.pending, b.order[0])
+		b.order = b.order[1:]
+	}
+
+	b.pending[token] = p
+	b.order = append(b.order, token)
+	return token
+}
+
+// cut returns the start of text up to maxBytes long. It ends after the last line break if there
+// is one in the second half, and never in the middle of a UTF-8 character.
+func cut(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	if i := strings.LastIndexByte(text[:end], '\n'); i >= end/2 {
+		end = i + 1
+	}
+	if end == 0 {
+		// A single character larger than the budget is returned whole, so that there is progress
+		_, size := utf8.DecodeRuneInString(text)
+		end = size
+	}
+	return text[:end]
+}
+
+// sessionID returns the ID of the client session of the tool call, empty if there is none.
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// contentText returns the text of text contents and embedded text resources.
+func contentText(content mcp.Content) (string, bool) {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text, true
+	case mcp.EmbeddedResource:
+		if resource, ok := c.Resource.(mcp.TextResourceContents); ok {
+			return resource.Text, true
+		}
+	}
+	return "", false
+}
+
+func mustText(content mcp.Content) string {
+	text, _ := contentText(content)
+	return text
+}
+
+// withText returns a copy of a content returned by contentText with its text replaced.
+func withText(content mcp.Content, text string) mcp.Content {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		c.Text = text
+		return c
+	case mcp.EmbeddedResource:
+		resource := c.Resource.(mcp.TextResourceContents)
+		resource.Text = text
+		c.Resource = resource
+		return c
+	}
+	return content
+}
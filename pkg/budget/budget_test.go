@@ -0,0 +1,170 @@
+package budget
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callTool(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), tool string, arguments map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Name = tool
+	request.Params.Arguments = arguments
+	result, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	return result
+}
+
+// continuationToken returns the token in the note of a truncated result, empty for the last part.
+func continuationToken(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	note := result.Content[len(result.Content)-1].(mcp.TextContent).Text
+	_, token, ok := strings.Cut(note, ContinuationArgument+` "`)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSuffix(token, `".`)
+}
+
+func Test_ToolMiddleware(t *testing.T) {
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", 9)
+	}
+	full := strings.Join(lines, "\n")
+
+	calls := 0
+	b := New(Config{MaxSize: 100, ToolMaxSizes: map[string]int{"get_me": 0}})
+	handler := b.ToolMiddleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText(full), nil
+	})
+
+	result := callTool(t, handler, "list_issues", map[string]any{"owner": "octo"})
+	require.Len(t, result.Content, 2)
+	part := result.Content[0].(mcp.TextContent).Text
+	assert.Equal(t, strings.Join(lines[:10], "\n")+"\n", part, "cut after the last line break within the budget")
+	assert.Contains(t, result.Content[1].(mcp.TextContent).Text, "this is bytes 0 to 100 of 999, 899 bytes were cut")
+
+	// The parts put back together are the whole result, without calling the tool again
+	got := part
+	for token := continuationToken(t, result); token != ""; token = continuationToken(t, result) {
+		result = callTool(t, handler, "list_issues", map[string]any{ContinuationArgument: token})
+		require.False(t, result.IsError, result.Content)
+		got += result.Content[0].(mcp.TextContent).Text
+	}
+	assert.Equal(t, full, got)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "This is the last part of a truncated result, bytes 900 to 999 of 999.", result.Content[1].(mcp.TextContent).Text)
+
+	// Tokens only continue results of the same tool
+	result = callTool(t, handler, "list_issues", nil)
+	result = callTool(t, handler, "get_issue", map[string]any{ContinuationArgument: continuationToken(t, result)})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "continuation token does not belong to a result of get_issue in this session", result.Content[0].(mcp.TextContent).Text)
+
+	result = callTool(t, handler, "list_issues", map[string]any{ContinuationArgument: "unknown"})
+	assert.True(t, result.IsError)
+
+	// Tools with a budget of zero are not truncated
+	result = callTool(t, handler, "get_me", nil)
+	assert.Equal(t, full, result.Content[0].(mcp.TextContent).Text)
+}
+
+func Test_CheckContinuations(t *testing.T) {
+	b := New(Config{MaxSize: 10})
+	denied := false
+	var checked map[string]any
+	b.CheckContinuations(func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			checked = request.GetArguments()
+			if denied {
+				return mcp.NewToolResultError("denied"), nil
+			}
+			return next(ctx, request)
+		}
+	})
+	handler := b.ToolMiddleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(strings.Repeat("x", 30)), nil
+	})
+
+	result := callTool(t, handler, "list_issues", map[string]any{"owner": "octo", "repo": "hello"})
+	result = callTool(t, handler, "list_issues", map[string]any{ContinuationArgument: continuationToken(t, result), "owner": "other"})
+	require.False(t, result.IsError)
+	assert.Equal(t, map[string]any{"owner": "octo", "repo": "hello"}, checked, "the arguments of the first call are checked")
+
+	// Continuations are checked again, for the rules in force when they are made
+	denied = true
+	result = callTool(t, handler, "list_issues", map[string]any{ContinuationArgument: continuationToken(t, result)})
+	require.True(t, result.IsError)
+	assert.Equal(t, "denied", result.Content[0].(mcp.TextContent).Text)
+}
+
+func Test_ToolMiddlewareResource(t *testing.T) {
+	b := New(Config{MaxSize: 10, Unit: Tokens})
+	handler := b.ToolMiddleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultResource("successfully downloaded text file", mcp.TextResourceContents{
+			URI:      "repo://octo/hello-world/contents/README.md",
+			MIMEType: "text/markdown",
+			Text:     strings.Repeat("é", 100),
+		}), nil
+	})
+
+	result := callTool(t, handler, "get_file_contents", nil)
+	require.Len(t, result.Content, 3)
+	assert.Equal(t, "successfully downloaded text file", result.Content[0].(mcp.TextContent).Text)
+	resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	assert.Equal(t, "repo://octo/hello-world/contents/README.md", resource.URI)
+	// The message leaves 7 of the 40 bytes, which is less than half the budget
+	assert.Equal(t, strings.Repeat("é", 10), resource.Text, "never cut within a character")
+
+	result = callTool(t, handler, "get_file_contents", map[string]any{ContinuationArgument: continuationToken(t, result)})
+	resource = result.Content[0].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	assert.Equal(t, strings.Repeat("é", 20), resource.Text)
+}
+
+func Test_Expiry(t *testing.T) {
+	now := time.Now()
+	b := New(Config{MaxSize: 10, TTL: time.Minute, MaxPending: 2})
+	b.now = func() time.Time { return now }
+	handler := b.ToolMiddleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(strings.Repeat("x", 100)), nil
+	})
+
+	first := continuationToken(t, callTool(t, handler, "list_issues", nil))
+	second := continuationToken(t, callTool(t, handler, "list_issues", nil))
+	third := continuationToken(t, callTool(t, handler, "list_issues", nil))
+
+	assert.True(t, callTool(t, handler, "list_issues", map[string]any{ContinuationArgument: first}).IsError, "dropped as the oldest")
+	assert.False(t, callTool(t, handler, "list_issues", map[string]any{ContinuationArgument: second}).IsError)
+
+	now = now.Add(2 * time.Minute)
+	assert.True(t, callTool(t, handler, "list_issues", map[string]any{ContinuationArgument: third}).IsError, "expired")
+}
+
+func Test_ToolFilter(t *testing.T) {
+	b := New(Config{ToolMaxSizes: map[string]int{"get_pull_request_diff": 1000}})
+	tools := b.ToolFilter(context.Background(), []mcp.Tool{
+		mcp.NewTool("get_pull_request_diff", mcp.WithString("owner")),
+		mcp.NewTool("get_me"),
+	})
+	assert.Contains(t, tools[0].InputSchema.Properties, ContinuationArgument)
+	assert.Contains(t, tools[0].InputSchema.Properties, "owner")
+	assert.NotContains(t, tools[1].InputSchema.Properties, ContinuationArgument)
+}
+
+func Test_ParseUnit(t *testing.T) {
+	unit, err := ParseUnit("tokens")
+	require.NoError(t, err)
+	assert.Equal(t, Tokens, unit)
+
+	_, err = ParseUnit("lines")
+	require.EqualError(t, err, `unknown size unit "lines", must be "bytes" or "tokens"`)
+}
@@ -0,0 +1,186 @@
+// Package dryrun intercepts the requests that would change data on GitHub, so that write tools can
+// report what they would do without doing it.
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Mutation is a request that was intercepted instead of being sent to GitHub.
+type Mutation struct {
+	// API is the GitHub API the request targets, such as "rest" or "graphql"
+	API    string `json:"api"`
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url,omitempty"`
+	// Endpoint is the URL path with identifiers replaced by placeholders
+	Endpoint string `json:"endpoint,omitempty"`
+	// Body is the JSON request body of REST requests
+	Body any `json:"body,omitempty"`
+	// Query and Variables make up GraphQL mutations
+	Query     string `json:"query,omitempty"`
+	Variables any    `json:"variables,omitempty"`
+}
+
+// Report is the result of a write tool called in dry-run mode.
+type Report struct {
+	DryRun    bool       `json:"dry_run"`
+	Tool      string     `json:"tool"`
+	Message   string     `json:"message"`
+	Mutations []Mutation `json:"mutations"`
+}
+
+type recorderKey struct{}
+
+// recorder collects the mutations of a single tool call.
+type recorder struct {
+	mu        sync.Mutex
+	mutations []Mutation
+}
+
+func (r *recorder) add(mutation Mutation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mutations = append(r.mutations, mutation)
+}
+
+// Transport returns a transport that answers requests that would change data with a synthetic
+// success response instead of sending them, and records them for the tool call in the request
+// context. Reads, including GraphQL queries, are sent through transport. classify returns the
+// GitHub API a request targets and its endpoint template.
+func Transport(transport http.RoundTripper, classify func(req *http.Request) (api, endpoint string)) http.RoundTripper {
+	return &dryRunTransport{
+		transport: transport,
+		classify:  classify,
+	}
+}
+
+type dryRunTransport struct {
+	transport http.RoundTripper
+	classify  func(req *http.Request) (api, endpoint string)
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+		return t.transport.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	api, endpoint := t.classify(req)
+	mutation := Mutation{API: api}
+	if api == "graphql" {
+		var request struct {
+			Query     string `json:"query"`
+			Variables any    `json:"variables"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil {
+			return nil, fmt.Errorf("failed to parse GraphQL request: %w", err)
+		}
+		if !strings.HasPrefix(strings.TrimSpace(request.Query), "mutation") {
+			req = req.Clone(req.Context())
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			return t.transport.RoundTrip(req)
+		}
+		mutation.Query = request.Query
+		mutation.Variables = request.Variables
+	} else {
+		mutation.Method = req.Method
+		mutation.URL = req.URL.String()
+		mutation.Endpoint = endpoint
+		if len(body) > 0 {
+			var decoded any
+			if err := json.Unmarshal(body, &decoded); err != nil {
+				decoded = string(body)
+			}
+			mutation.Body = decoded
+		}
+	}
+
+	if r, ok := req.Context().Value(recorderKey{}).(*recorder); ok {
+		r.add(mutation)
+	}
+	return syntheticResponse(req, api), nil
+}
+
+// syntheticResponse answers an intercepted request with the status GitHub usually answers its
+// method with, and an empty object, so that tools making several requests carry on.
+func syntheticResponse(req *http.Request, api string) *http.Response {
+	status, body := http.StatusOK, "{}"
+	switch {
+	case api == "graphql":
+		body = `{"data":{}}`
+	case req.Method == http.MethodPost:
+		status = http.StatusCreated
+	case req.Method == http.MethodDelete:
+		status, body = http.StatusNoContent, ""
+	}
+
+	header := make(http.Header)
+	if body != "" {
+		header.Set("Content-Type", "application/json")
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// ToolMiddleware returns a middleware that reports the mutations of the tools for which isWrite
+// returns true instead of their results. Calls that fail before attempting a mutation, for
+// example because of invalid arguments or a missing ref, return their error as usual.
+func ToolMiddleware(isWrite func(tool string) bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !isWrite(request.Params.Name) {
+				return next(ctx, request)
+			}
+
+			r := &recorder{}
+			result, err := next(context.WithValue(ctx, recorderKey{}, r), request)
+
+			r.mu.Lock()
+			mutations := r.mutations
+			r.mu.Unlock()
+			if len(mutations) == 0 {
+				return result, err
+			}
+
+			report, err := json.Marshal(Report{
+				DryRun:    true,
+				Tool:      request.Params.Name,
+				Message:   "Dry run: no changes were made. These are the requests the tool would have sent to GitHub.",
+				Mutations: mutations,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal dry run report: %w", err)
+			}
+			return mcp.NewToolResultText(string(report)), nil
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func classify(req *http.Request) (string, string) {
+	if req.URL.Path == "/graphql" {
+		return "graphql", "/graphql"
+	}
+	return "rest", req.URL.Path
+}
+
+func Test_Transport(t *testing.T) {
+	var sent []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sent = append(sent, r.Method+" "+r.URL.Path+" "+string(body))
+		_, _ = w.Write([]byte(`{"data":{"repository":{"id":"R_1"}}}`))
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: Transport(http.DefaultTransport, classify)}
+	r := &recorder{}
+	ctx := context.WithValue(context.Background(), recorderKey{}, r)
+
+	do := func(method, path, body string) *http.Response {
+		req, err := http.NewRequestWithContext(ctx, method, ts.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := do(http.MethodGet, "/repos/octo/hello-world", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/repos/octo/hello-world/issues", `{"title":"Bug"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(body))
+
+	resp = do(http.MethodDelete, "/repos/octo/hello-world/contents/README.md", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/graphql", `{"query":"query{repository(owner:\"octo\",name:\"hello-world\"){id}}"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/graphql", `{"query":"mutation($input:MarkPullRequestReadyForReviewInput!){markPullRequestReadyForReview(input:$input){clientMutationId}}","variables":{"input":{"pullRequestId":"PR_1"}}}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, []string{
+		"GET /repos/octo/hello-world ",
+		`POST /graphql {"query":"query{repository(owner:\"octo\",name:\"hello-world\"){id}}"}`,
+	}, sent, "only reads should be sent")
+
+	assert.Equal(t, []Mutation{
+		{
+			API:      "rest",
+			Method:   http.MethodPost,
+			URL:      ts.URL + "/repos/octo/hello-world/issues",
+			Endpoint: "/repos/octo/hello-world/issues",
+			Body:     map[string]any{"title": "Bug"},
+		},
+		{
+			API:      "rest",
+			Method:   http.MethodDelete,
+			URL:      ts.URL + "/repos/octo/hello-world/contents/README.md",
+			Endpoint: "/repos/octo/hello-world/contents/README.md",
+		},
+		{
+			API:       "graphql",
+			Query:     "mutation($input:MarkPullRequestReadyForReviewInput!){markPullRequestReadyForReview(input:$input){clientMutationId}}",
+			Variables: map[string]any{"input": map[string]any{"pullRequestId": "PR_1"}},
+		},
+	}, r.mutations)
+}
+
+func Test_ToolMiddleware(t *testing.T) {
+	isWrite := func(tool string) bool { return tool != "get_issue" }
+	middleware := ToolMiddleware(isWrite)
+
+	call := func(name string, handler func(ctx context.Context) (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = name
+		return middleware(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handler(ctx)
+		})(context.Background(), request)
+	}
+	record := func(ctx context.Context, mutation Mutation) {
+		if r, ok := ctx.Value(recorderKey{}).(*recorder); ok {
+			r.add(mutation)
+		}
+	}
+
+	// Write tools report their mutations instead of their result
+	result, err := call("create_issue", func(ctx context.Context) (*mcp.CallToolResult, error) {
+		record(ctx, Mutation{API: "rest", Method: http.MethodPost, Endpoint: "/repos/{owner}/{repo}/issues"})
+		return nil, errors.New("unexpected response")
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var report Report
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, "create_issue", report.Tool)
+	assert.Equal(t, []Mutation{{API: "rest", Method: http.MethodPost, Endpoint: "/repos/{owner}/{repo}/issues"}}, report.Mutations)
+
+	// Failures before any mutation are returned as usual
+	result, err = call("create_issue", func(_ context.Context) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("missing required parameter: title"), nil
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	// Read tools are not recorded
+	result, err = call("get_issue", func(ctx context.Context) (*mcp.CallToolResult, error) {
+		_, recording := ctx.Value(recorderKey{}).(*recorder)
+		assert.False(t, recording)
+		return mcp.NewToolResultText("issue"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "issue", result.Content[0].(mcp.TextContent).Text)
+}
@@ -60,24 +60,27 @@ func ListWorkflows(getClient GetClientFn, t translations.TranslationHelperFunc)
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
-			// Set up list options
-			opts := &github.ListOptions{
-				PerPage: pagination.PerPage,
-				Page:    pagination.Page,
-			}
-
-			workflows, resp, err := client.Actions.ListWorkflows(ctx, owner, repo, opts)
+			var workflows *github.Workflows
+			all, resp, pages, err := fetchPages(pagination, func(opts github.ListOptions) ([]*github.Workflow, *github.Response, error) {
+				page, resp, err := client.Actions.ListWorkflows(ctx, owner, repo, &opts)
+				if err != nil {
+					return nil, resp, err
+				}
+				workflows = page
+				return page.Workflows, resp, nil
+			})
 			if err != nil {
 				return nil, fmt.Errorf("failed to list workflows: %w", err)
 			}
 			defer func() { _ = resp.Body.Close() }()
+			workflows.Workflows = all
 
 			r, err := json.Marshal(workflows)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -149,6 +152,7 @@ func ListWorkflowRuns(getClient GetClientFn, t translations.TranslationHelperFun
 				mcp.Enum("queued", "in_progress", "completed", "requested", "waiting"),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -188,6 +192,11 @@ func ListWorkflowRuns(getClient GetClientFn, t translations.TranslationHelperFun
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			shape, err := workflowRunListShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -199,24 +208,30 @@ func ListWorkflowRuns(getClient GetClientFn, t translations.TranslationHelperFun
 				Branch: branch,
 				Event:  event,
 				Status: status,
-				ListOptions: github.ListOptions{
-					PerPage: pagination.PerPage,
-					Page:    pagination.Page,
-				},
 			}
 
-			workflowRuns, resp, err := client.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflowID, opts)
+			var workflowRuns *github.WorkflowRuns
+			all, resp, pages, err := fetchPages(pagination, func(listOpts github.ListOptions) ([]*github.WorkflowRun, *github.Response, error) {
+				opts.ListOptions = listOpts
+				page, resp, err := client.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflowID, opts)
+				if err != nil {
+					return nil, resp, err
+				}
+				workflowRuns = page
+				return page.WorkflowRuns, resp, nil
+			})
 			if err != nil {
 				return nil, fmt.Errorf("failed to list workflow runs: %w", err)
 			}
 			defer func() { _ = resp.Body.Close() }()
+			workflowRuns.WorkflowRuns = all
 
-			r, err := json.Marshal(workflowRuns)
+			r, err := shape.marshal(workflowRuns)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -339,6 +354,7 @@ func GetWorkflowRun(getClient GetClientFn, t translations.TranslationHelperFunc)
 				mcp.Required(),
 				mcp.Description("The unique identifier of the workflow run"),
 			),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -355,6 +371,11 @@ func GetWorkflowRun(getClient GetClientFn, t translations.TranslationHelperFunc)
 			}
 			runID := int64(runIDInt)
 
+			shape, err := workflowRunShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -366,7 +387,7 @@ func GetWorkflowRun(getClient GetClientFn, t translations.TranslationHelperFunc)
 			}
 			defer func() { _ = resp.Body.Close() }()
 
-			r, err := json.Marshal(workflowRun)
+			r, err := shape.marshal(workflowRun)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -466,6 +487,7 @@ func ListWorkflowJobs(getClient GetClientFn, t translations.TranslationHelperFun
 				mcp.Enum("latest", "all"),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -494,6 +516,11 @@ func ListWorkflowJobs(getClient GetClientFn, t translations.TranslationHelperFun
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			shape, err := workflowJobListShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -502,17 +529,23 @@ func ListWorkflowJobs(getClient GetClientFn, t translations.TranslationHelperFun
 			// Set up list options
 			opts := &github.ListWorkflowJobsOptions{
 				Filter: filter,
-				ListOptions: github.ListOptions{
-					PerPage: pagination.PerPage,
-					Page:    pagination.Page,
-				},
 			}
 
-			jobs, resp, err := client.Actions.ListWorkflowJobs(ctx, owner, repo, runID, opts)
+			var jobs *github.Jobs
+			all, resp, pages, err := fetchPages(pagination, func(listOpts github.ListOptions) ([]*github.WorkflowJob, *github.Response, error) {
+				opts.ListOptions = listOpts
+				page, resp, err := client.Actions.ListWorkflowJobs(ctx, owner, repo, runID, opts)
+				if err != nil {
+					return nil, resp, err
+				}
+				jobs = page
+				return page.Jobs, resp, nil
+			})
 			if err != nil {
 				return nil, fmt.Errorf("failed to list workflow jobs: %w", err)
 			}
 			defer func() { _ = resp.Body.Close() }()
+			jobs.Jobs = all
 
 			// Add optimization tip for failed job debugging
 			response := map[string]any{
@@ -520,17 +553,17 @@ func ListWorkflowJobs(getClient GetClientFn, t translations.TranslationHelperFun
 				"optimization_tip": "For debugging failed jobs, consider using get_job_logs with failed_only=true and run_id=" + fmt.Sprintf("%d", runID) + " to get logs directly without needing to list jobs first",
 			}
 
-			r, err := json.Marshal(response)
+			r, err := shape.marshal(response)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
 // GetJobLogs creates a tool to download logs for a specific workflow job or efficiently get all failed job logs for a workflow run
-func GetJobLogs(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func GetJobLogs(getClient GetClientFn, getHTTPClient GetHTTPClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_job_logs",
 			mcp.WithDescription(t("TOOL_GET_JOB_LOGS_DESCRIPTION", "Download logs for a specific workflow job or efficiently get all failed job logs for a workflow run")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -602,6 +635,10 @@ func GetJobLogs(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
+			httpClient, err := getHTTPClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get HTTP client: %w", err)
+			}
 
 			// Validate parameters
 			if failedOnly && runID == 0 {
@@ -613,10 +650,10 @@ func GetJobLogs(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 
 			if failedOnly && runID > 0 {
 				// Handle failed-only mode: get logs for all failed jobs in the workflow run
-				return handleFailedJobLogs(ctx, client, owner, repo, int64(runID), returnContent, tailLines)
+				return handleFailedJobLogs(ctx, client, httpClient, owner, repo, int64(runID), returnContent, tailLines)
 			} else if jobID > 0 {
 				// Handle single job mode
-				return handleSingleJobLogs(ctx, client, owner, repo, int64(jobID), returnContent, tailLines)
+				return handleSingleJobLogs(ctx, client, httpClient, owner, repo, int64(jobID), returnContent, tailLines)
 			}
 
 			return mcp.NewToolResultError("Either job_id must be provided for single job logs, or run_id with failed_only=true for failed job logs"), nil
@@ -624,7 +661,7 @@ func GetJobLogs(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 }
 
 // handleFailedJobLogs gets logs for all failed jobs in a workflow run
-func handleFailedJobLogs(ctx context.Context, client *github.Client, owner, repo string, runID int64, returnContent bool, tailLines int) (*mcp.CallToolResult, error) {
+func handleFailedJobLogs(ctx context.Context, client *github.Client, httpClient *http.Client, owner, repo string, runID int64, returnContent bool, tailLines int) (*mcp.CallToolResult, error) {
 	// First, get all jobs for the workflow run
 	jobs, resp, err := client.Actions.ListWorkflowJobs(ctx, owner, repo, runID, &github.ListWorkflowJobsOptions{
 		Filter: "latest",
@@ -656,7 +693,7 @@ func handleFailedJobLogs(ctx context.Context, client *github.Client, owner, repo
 	// Collect logs for all failed jobs
 	var logResults []map[string]any
 	for _, job := range failedJobs {
-		jobResult, resp, err := getJobLogData(ctx, client, owner, repo, job.GetID(), job.GetName(), returnContent, tailLines)
+		jobResult, resp, err := getJobLogData(ctx, client, httpClient, owner, repo, job.GetID(), job.GetName(), returnContent, tailLines)
 		if err != nil {
 			// Continue with other jobs even if one fails
 			jobResult = map[string]any{
@@ -718,8 +755,8 @@ func handleFailedJobLogs(ctx context.Context, client *github.Client, owner, repo
 }
 
 // handleSingleJobLogs gets logs for a single job
-func handleSingleJobLogs(ctx context.Context, client *github.Client, owner, repo string, jobID int64, returnContent bool, tailLines int) (*mcp.CallToolResult, error) {
-	jobResult, resp, err := getJobLogData(ctx, client, owner, repo, jobID, "", returnContent, tailLines)
+func handleSingleJobLogs(ctx context.Context, client *github.Client, httpClient *http.Client, owner, repo string, jobID int64, returnContent bool, tailLines int) (*mcp.CallToolResult, error) {
+	jobResult, resp, err := getJobLogData(ctx, client, httpClient, owner, repo, jobID, "", returnContent, tailLines)
 	if err != nil {
 		return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get job logs", resp, err), nil
 	}
@@ -733,7 +770,7 @@ func handleSingleJobLogs(ctx context.Context, client *github.Client, owner, repo
 }
 
 // getJobLogData retrieves log data for a single job, either as URL or content
-func getJobLogData(ctx context.Context, client *github.Client, owner, repo string, jobID int64, jobName string, returnContent bool, tailLines int) (map[string]any, *github.Response, error) {
+func getJobLogData(ctx context.Context, client *github.Client, httpClient *http.Client, owner, repo string, jobID int64, jobName string, returnContent bool, tailLines int) (map[string]any, *github.Response, error) {
 	// Get the download URL for the job logs
 	url, resp, err := client.Actions.GetWorkflowJobLogs(ctx, owner, repo, jobID, 1)
 	if err != nil {
@@ -750,7 +787,7 @@ func getJobLogData(ctx context.Context, client *github.Client, owner, repo strin
 
 	if returnContent {
 		// Download and return the actual log content
-		content, originalLength, httpResp, err := downloadLogContent(url.String(), tailLines) //nolint:bodyclose // Response body is closed in downloadLogContent, but we need to return httpResp
+		content, originalLength, httpResp, err := downloadLogContent(ctx, httpClient, url.String(), tailLines) //nolint:bodyclose // Response body is closed in downloadLogContent, but we need to return httpResp
 		if err != nil {
 			// To keep the return value consistent wrap the response as a GitHub Response
 			ghRes := &github.Response{
@@ -772,8 +809,12 @@ func getJobLogData(ctx context.Context, client *github.Client, owner, repo strin
 }
 
 // downloadLogContent downloads the actual log content from a GitHub logs URL
-func downloadLogContent(logURL string, tailLines int) (string, int, *http.Response, error) {
-	httpResp, err := http.Get(logURL) //nolint:gosec // URLs are provided by GitHub API and are safe
+func downloadLogContent(ctx context.Context, httpClient *http.Client, logURL string, tailLines int) (string, int, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logURL, nil)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to create log request: %w", err)
+	}
+	httpResp, err := httpClient.Do(req) //nolint:gosec // URLs are provided by GitHub API and are safe
 	if err != nil {
 		return "", 0, httpResp, fmt.Errorf("failed to download logs: %w", err)
 	}
@@ -1052,24 +1093,27 @@ func ListWorkflowRunArtifacts(getClient GetClientFn, t translations.TranslationH
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
-			// Set up list options
-			opts := &github.ListOptions{
-				PerPage: pagination.PerPage,
-				Page:    pagination.Page,
-			}
-
-			artifacts, resp, err := client.Actions.ListWorkflowRunArtifacts(ctx, owner, repo, runID, opts)
+			var artifacts *github.ArtifactList
+			all, resp, pages, err := fetchPages(pagination, func(opts github.ListOptions) ([]*github.Artifact, *github.Response, error) {
+				page, resp, err := client.Actions.ListWorkflowRunArtifacts(ctx, owner, repo, runID, &opts)
+				if err != nil {
+					return nil, resp, err
+				}
+				artifacts = page
+				return page.Artifacts, resp, nil
+			})
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to list workflow run artifacts", resp, err), nil
 			}
 			defer func() { _ = resp.Body.Close() }()
+			artifacts.Artifacts = all
 
 			r, err := json.Marshal(artifacts)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -784,7 +784,7 @@ func Test_GetWorkflowRunUsage(t *testing.T) {
 func Test_GetJobLogs(t *testing.T) {
 	// Verify tool definition once
 	mockClient := github.NewClient(nil)
-	tool, _ := GetJobLogs(stubGetClientFn(mockClient), translations.NullTranslationHelper)
+	tool, _ := GetJobLogs(stubGetClientFn(mockClient), stubGetHTTPClientFn(http.DefaultClient), translations.NullTranslationHelper)
 
 	assert.Equal(t, "get_job_logs", tool.Name)
 	assert.NotEmpty(t, tool.Description)
@@ -1013,7 +1013,7 @@ func Test_GetJobLogs(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Setup client with mock
 			client := github.NewClient(tc.mockedClient)
-			_, handler := GetJobLogs(stubGetClientFn(client), translations.NullTranslationHelper)
+			_, handler := GetJobLogs(stubGetClientFn(client), stubGetHTTPClientFn(http.DefaultClient), translations.NullTranslationHelper)
 
 			// Create call request
 			request := createMCPRequest(tc.requestArgs)
@@ -1072,7 +1072,7 @@ func Test_GetJobLogs_WithContentReturn(t *testing.T) {
 	)
 
 	client := github.NewClient(mockedClient)
-	_, handler := GetJobLogs(stubGetClientFn(client), translations.NullTranslationHelper)
+	_, handler := GetJobLogs(stubGetClientFn(client), stubGetHTTPClientFn(http.DefaultClient), translations.NullTranslationHelper)
 
 	request := createMCPRequest(map[string]any{
 		"owner":          "owner",
@@ -1119,7 +1119,7 @@ func Test_GetJobLogs_WithContentReturnAndTailLines(t *testing.T) {
 	)
 
 	client := github.NewClient(mockedClient)
-	_, handler := GetJobLogs(stubGetClientFn(client), translations.NullTranslationHelper)
+	_, handler := GetJobLogs(stubGetClientFn(client), stubGetHTTPClientFn(http.DefaultClient), translations.NullTranslationHelper)
 
 	request := createMCPRequest(map[string]any{
 		"owner":          "owner",
@@ -2,7 +2,6 @@ package github
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -34,6 +33,7 @@ func GetDependabotAlert(getClient GetClientFn, t translations.TranslationHelperF
 				mcp.Required(),
 				mcp.Description("The number of the alert."),
 			),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -49,6 +49,11 @@ func GetDependabotAlert(getClient GetClientFn, t translations.TranslationHelperF
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			shape, err := dependabotAlertShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -72,7 +77,7 @@ func GetDependabotAlert(getClient GetClientFn, t translations.TranslationHelperF
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get alert: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(alert)
+			r, err := shape.marshal(alert)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal alert: %w", err)
 			}
@@ -106,6 +111,7 @@ func ListDependabotAlerts(getClient GetClientFn, t translations.TranslationHelpe
 				mcp.Description("Filter dependabot alerts by severity"),
 				mcp.Enum("low", "medium", "high", "critical"),
 			),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -125,6 +131,11 @@ func ListDependabotAlerts(getClient GetClientFn, t translations.TranslationHelpe
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			shape, err := dependabotAlertShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -151,7 +162,7 @@ func ListDependabotAlerts(getClient GetClientFn, t translations.TranslationHelpe
 				return mcp.NewToolResultError(fmt.Sprintf("failed to list alerts: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(alerts)
+			r, err := shape.marshal(alerts)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal alerts: %w", err)
 			}
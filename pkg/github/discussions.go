@@ -194,12 +194,6 @@ func ListDiscussions(getGQLClient GetGQLClientFn, t translations.TranslationHelp
 			vars := map[string]interface{}{
 				"owner": githubv4.String(owner),
 				"repo":  githubv4.String(repo),
-				"first": githubv4.Int(*paginationParams.First),
-			}
-			if paginationParams.After != nil {
-				vars["after"] = githubv4.String(*paginationParams.After)
-			} else {
-				vars["after"] = (*githubv4.String)(nil)
 			}
 
 			// this is an extra check in case the tool description is misinterpreted, because
@@ -214,22 +208,35 @@ func ListDiscussions(getGQLClient GetGQLClientFn, t translations.TranslationHelp
 				vars["categoryId"] = *categoryID
 			}
 
-			discussionQuery := getQueryType(useOrdering, categoryID)
-			if err := client.Query(ctx, discussionQuery, vars); err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
-			}
-
-			// Extract and convert all discussion nodes using the common interface
-			var discussions []*github.Discussion
-			var pageInfo PageInfoFragment
 			var totalCount githubv4.Int
-			if queryResult, ok := discussionQuery.(DiscussionQueryResult); ok {
+			discussions, pageInfo, pages, err := fetchCursorPages(pagination, *paginationParams.First, func(first int32, after *string) ([]*github.Discussion, PageInfoFragment, error) {
+				vars["first"] = githubv4.Int(first)
+				if after != nil {
+					vars["after"] = githubv4.String(*after)
+				} else {
+					vars["after"] = (*githubv4.String)(nil)
+				}
+
+				discussionQuery := getQueryType(useOrdering, categoryID)
+				if err := client.Query(ctx, discussionQuery, vars); err != nil {
+					return nil, PageInfoFragment{}, err
+				}
+
+				// Extract and convert all discussion nodes using the common interface
+				var discussions []*github.Discussion
+				queryResult, ok := discussionQuery.(DiscussionQueryResult)
+				if !ok {
+					return nil, PageInfoFragment{}, nil
+				}
 				fragment := queryResult.GetDiscussionFragment()
 				for _, node := range fragment.Nodes {
 					discussions = append(discussions, fragmentToDiscussion(node))
 				}
-				pageInfo = fragment.PageInfo
 				totalCount = fragment.TotalCount
+				return discussions, fragment.PageInfo, nil
+			})
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
 			// Create response with pagination info
@@ -248,7 +255,7 @@ func ListDiscussions(getGQLClient GetGQLClientFn, t translations.TranslationHelp
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal discussions: %w", err)
 			}
-			return mcp.NewToolResultText(string(out)), nil
+			return pages.result(out)
 		}
 }
 
@@ -376,54 +383,68 @@ func GetDiscussionComments(getGQLClient GetGQLClientFn, t translations.Translati
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get GitHub GQL client: %v", err)), nil
 			}
 
-			var q struct {
-				Repository struct {
-					Discussion struct {
-						Comments struct {
-							Nodes []struct {
-								Body githubv4.String
-							}
-							PageInfo struct {
-								HasNextPage     githubv4.Boolean
-								HasPreviousPage githubv4.Boolean
-								StartCursor     githubv4.String
-								EndCursor       githubv4.String
-							}
-							TotalCount int
-						} `graphql:"comments(first: $first, after: $after)"`
-					} `graphql:"discussion(number: $discussionNumber)"`
-				} `graphql:"repository(owner: $owner, name: $repo)"`
-			}
-			vars := map[string]interface{}{
-				"owner":            githubv4.String(params.Owner),
-				"repo":             githubv4.String(params.Repo),
-				"discussionNumber": githubv4.Int(params.DiscussionNumber),
-				"first":            githubv4.Int(*paginationParams.First),
-			}
-			if paginationParams.After != nil {
-				vars["after"] = githubv4.String(*paginationParams.After)
-			} else {
-				vars["after"] = (*githubv4.String)(nil)
-			}
-			if err := client.Query(ctx, &q, vars); err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
-			}
+			var totalCount int
+			comments, pageInfo, pages, err := fetchCursorPages(pagination, *paginationParams.First, func(first int32, after *string) ([]*github.IssueComment, PageInfoFragment, error) {
+				var q struct {
+					Repository struct {
+						Discussion struct {
+							Comments struct {
+								Nodes []struct {
+									Body githubv4.String
+								}
+								PageInfo struct {
+									HasNextPage     githubv4.Boolean
+									HasPreviousPage githubv4.Boolean
+									StartCursor     githubv4.String
+									EndCursor       githubv4.String
+								}
+								TotalCount int
+							} `graphql:"comments(first: $first, after: $after)"`
+						} `graphql:"discussion(number: $discussionNumber)"`
+					} `graphql:"repository(owner: $owner, name: $repo)"`
+				}
+				vars := map[string]interface{}{
+					"owner":            githubv4.String(params.Owner),
+					"repo":             githubv4.String(params.Repo),
+					"discussionNumber": githubv4.Int(params.DiscussionNumber),
+					"first":            githubv4.Int(first),
+				}
+				if after != nil {
+					vars["after"] = githubv4.String(*after)
+				} else {
+					vars["after"] = (*githubv4.String)(nil)
+				}
+				if err := client.Query(ctx, &q, vars); err != nil {
+					return nil, PageInfoFragment{}, err
+				}
 
-			var comments []*github.IssueComment
-			for _, c := range q.Repository.Discussion.Comments.Nodes {
-				comments = append(comments, &github.IssueComment{Body: github.Ptr(string(c.Body))})
+				var comments []*github.IssueComment
+				for _, c := range q.Repository.Discussion.Comments.Nodes {
+					comments = append(comments, &github.IssueComment{Body: github.Ptr(string(c.Body))})
+				}
+				page := q.Repository.Discussion.Comments.PageInfo
+				totalCount = q.Repository.Discussion.Comments.TotalCount
+				return comments, PageInfoFragment{
+					HasNextPage:     bool(page.HasNextPage),
+					HasPreviousPage: bool(page.HasPreviousPage),
+					StartCursor:     page.StartCursor,
+					EndCursor:       page.EndCursor,
+				}, nil
+			})
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
 			// Create response with pagination info
 			response := map[string]interface{}{
 				"comments": comments,
 				"pageInfo": map[string]interface{}{
-					"hasNextPage":     q.Repository.Discussion.Comments.PageInfo.HasNextPage,
-					"hasPreviousPage": q.Repository.Discussion.Comments.PageInfo.HasPreviousPage,
-					"startCursor":     string(q.Repository.Discussion.Comments.PageInfo.StartCursor),
-					"endCursor":       string(q.Repository.Discussion.Comments.PageInfo.EndCursor),
+					"hasNextPage":     pageInfo.HasNextPage,
+					"hasPreviousPage": pageInfo.HasPreviousPage,
+					"startCursor":     string(pageInfo.StartCursor),
+					"endCursor":       string(pageInfo.EndCursor),
 				},
-				"totalCount": q.Repository.Discussion.Comments.TotalCount,
+				"totalCount": totalCount,
 			}
 
 			out, err := json.Marshal(response)
@@ -431,7 +452,7 @@ func GetDiscussionComments(getGQLClient GetGQLClientFn, t translations.Translati
 				return nil, fmt.Errorf("failed to marshal comments: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(out)), nil
+			return pages.result(out)
 		}
 }
 
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"sync"
 
 	"github.com/github/github-mcp-server/pkg/toolsets"
 	"github.com/github/github-mcp-server/pkg/translations"
@@ -19,7 +21,7 @@ func ToolsetEnum(toolsetGroup *toolsets.ToolsetGroup) mcp.PropertyOption {
 	return mcp.Enum(toolsetNames...)
 }
 
-func EnableToolset(s *server.MCPServer, toolsetGroup *toolsets.ToolsetGroup, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func EnableToolset(s *server.MCPServer, toolsetGroup *toolsets.ToolsetGroup, sessions *SessionTools, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("enable_toolset",
 			mcp.WithDescription(t("TOOL_ENABLE_TOOLSET_DESCRIPTION", "Enable one of the sets of tools the GitHub MCP server provides, use get_toolset_tools and list_available_toolsets first to see what this will enable")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -33,7 +35,7 @@ func EnableToolset(s *server.MCPServer, toolsetGroup *toolsets.ToolsetGroup, t t
 				ToolsetEnum(toolsetGroup),
 			),
 		),
-		func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			// We need to convert the toolsets back to a map for JSON serialization
 			toolsetName, err := RequiredParam[string](request, "toolset")
 			if err != nil {
@@ -43,42 +45,204 @@ func EnableToolset(s *server.MCPServer, toolsetGroup *toolsets.ToolsetGroup, t t
 			if toolset == nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Toolset %s not found", toolsetName)), nil
 			}
-			if toolset.Enabled {
+			if toolsetEnabled(ctx, toolset) {
 				return mcp.NewToolResultText(fmt.Sprintf("Toolset %s is already enabled", toolsetName)), nil
 			}
 
-			toolset.Enabled = true
+			session, ok := toolsSession(ctx)
+			if !ok {
+				// The session shares the server's tools, which is only the case for the single stdio
+				// client, so enabling the toolset globally only affects this client.
+				toolset.Enabled = true
+				s.AddTools(toolset.GetActiveTools()...)
+				return mcp.NewToolResultText(fmt.Sprintf("Toolset %s enabled", toolsetName)), nil
+			}
 
-			// caution: this currently affects the global tools and notifies all clients:
-			//
-			// Send notification to all initialized sessions
-			// s.sendNotificationToAllClients("notifications/tools/list_changed", nil)
-			s.AddTools(toolset.GetActiveTools()...)
+			sessions.update(ctx, s, session, func(tools map[string]server.ServerTool) {
+				for _, st := range toolset.GetAvailableTools() {
+					tools[st.Tool.Name] = st
+				}
+			})
 
 			return mcp.NewToolResultText(fmt.Sprintf("Toolset %s enabled", toolsetName)), nil
 		}
 }
 
+func DisableToolset(s *server.MCPServer, toolsetGroup *toolsets.ToolsetGroup, sessions *SessionTools, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("disable_toolset",
+			mcp.WithDescription(t("TOOL_DISABLE_TOOLSET_DESCRIPTION", "Disable a toolset that was enabled with enable_toolset and is no longer needed, removing its tools to keep the list of available tools short. The toolset can be enabled again later")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title: t("TOOL_DISABLE_TOOLSET_USER_TITLE", "Disable a toolset"),
+				// Not modifying GitHub data so no need to show a warning
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("toolset",
+				mcp.Required(),
+				mcp.Description("The name of the toolset to disable"),
+				ToolsetEnum(toolsetGroup),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			toolsetName, err := RequiredParam[string](request, "toolset")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			toolset := toolsetGroup.Toolsets[toolsetName]
+			if toolset == nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Toolset %s not found", toolsetName)), nil
+			}
+			if !toolsetEnabled(ctx, toolset) {
+				return mcp.NewToolResultText(fmt.Sprintf("Toolset %s is already disabled", toolsetName)), nil
+			}
+
+			available := toolset.GetAvailableTools()
+			names := make([]string, 0, len(available))
+			for _, st := range available {
+				names = append(names, st.Tool.Name)
+			}
+
+			session, ok := toolsSession(ctx)
+			if !ok {
+				toolset.Enabled = false
+				s.DeleteTools(names...)
+				return mcp.NewToolResultText(fmt.Sprintf("Toolset %s disabled", toolsetName)), nil
+			}
+			if toolset.Enabled {
+				// The server's tools are shared by all sessions, and a session can only add to them.
+				return mcp.NewToolResultError(fmt.Sprintf("Toolset %s is enabled for all sessions by the server configuration and cannot be disabled", toolsetName)), nil
+			}
+
+			sessions.update(ctx, s, session, func(tools map[string]server.ServerTool) {
+				for _, name := range names {
+					delete(tools, name)
+				}
+			})
+
+			return mcp.NewToolResultText(fmt.Sprintf("Toolset %s disabled", toolsetName)), nil
+		}
+}
+
+// SessionTools keeps track of the sessions that enabled toolsets for themselves. Sessions of the
+// streamable HTTP transport that only send POST requests are never registered with the server, so
+// sessions are recorded when their tools change rather than when they register.
+type SessionTools struct {
+	// mu serializes changes to session tools, which are read, copied and written back
+	mu       sync.Mutex
+	sessions map[string]server.SessionWithTools
+}
+
+func NewSessionTools() *SessionTools {
+	return &SessionTools{sessions: make(map[string]server.SessionWithTools)}
+}
+
+// AddHooks adds the hook that forgets sessions when they are unregistered to hooks.
+func (st *SessionTools) AddHooks(hooks *server.Hooks) {
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		delete(st.sessions, session.SessionID())
+	})
+}
+
+// Clear disables the toolsets every session enabled for itself, such as when the tools they were
+// created from are replaced.
+func (st *SessionTools) Clear() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for id, session := range st.sessions {
+		// Sessions the client terminated have no tools left, and setting them would keep them around
+		if len(session.GetSessionTools()) > 0 {
+			session.SetSessionTools(make(map[string]server.ServerTool))
+		}
+		delete(st.sessions, id)
+	}
+}
+
+// len returns the number of sessions recorded.
+func (st *SessionTools) len() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return len(st.sessions)
+}
+
+// toolsSession returns the session of the tool call if it can hold tools of its own. Toolsets
+// enabled by such a session are only available to it.
+func toolsSession(ctx context.Context) (server.SessionWithTools, bool) {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithTools)
+	return session, ok
+}
+
+// toolsetEnabled reports whether the tools of the toolset are available to the session of the
+// tool call, either because the toolset is enabled for all sessions or because the session
+// enabled it.
+func toolsetEnabled(ctx context.Context, toolset *toolsets.Toolset) bool {
+	if toolset.Enabled {
+		return true
+	}
+	session, ok := toolsSession(ctx)
+	if !ok {
+		return false
+	}
+	sessionTools := session.GetSessionTools()
+	available := toolset.GetAvailableTools()
+	if len(sessionTools) == 0 || len(available) == 0 {
+		return false
+	}
+	for _, st := range available {
+		if _, ok := sessionTools[st.Tool.Name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// update changes the tools of the session with update, records the session while it has tools,
+// and tells the session's client that its tools changed. Only the session itself is notified, as
+// no other session sees its tools. Clients that don't listen for notifications pick the change up
+// on their next tools/list.
+func (st *SessionTools) update(ctx context.Context, s *server.MCPServer, session server.SessionWithTools, update func(tools map[string]server.ServerTool)) {
+	st.mu.Lock()
+	tools := make(map[string]server.ServerTool)
+	maps.Copy(tools, session.GetSessionTools())
+	update(tools)
+	session.SetSessionTools(tools)
+	st.sessions[session.SessionID()] = session
+	// Streamable HTTP sessions are not unregistered when the client terminates them, but lose their
+	// tools, so sessions without tools are forgotten here
+	for id, recorded := range st.sessions {
+		if len(recorded.GetSessionTools()) == 0 {
+			delete(st.sessions, id)
+		}
+	}
+	st.mu.Unlock()
+
+	_ = s.SendNotificationToClient(ctx, "notifications/tools/list_changed", nil)
+}
+
 func ListAvailableToolsets(toolsetGroup *toolsets.ToolsetGroup, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_available_toolsets",
-			mcp.WithDescription(t("TOOL_LIST_AVAILABLE_TOOLSETS_DESCRIPTION", "List all available toolsets this GitHub MCP server can offer, providing the enabled status of each. Use this when a task could be achieved with a GitHub tool and the currently available tools aren't enough. Call get_toolset_tools with these toolset names to discover specific tools you can call")),
+			mcp.WithDescription(t("TOOL_LIST_AVAILABLE_TOOLSETS_DESCRIPTION", "List all available toolsets this GitHub MCP server can offer, providing the enabled status of each, the number of tools it contains, whether they are all read-only and the approximate number of tokens their definitions take up. Use this when a task could be achieved with a GitHub tool and the currently available tools aren't enough. Call get_toolset_tools with these toolset names to discover specific tools you can call")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
 				Title:        t("TOOL_LIST_AVAILABLE_TOOLSETS_USER_TITLE", "List available toolsets"),
 				ReadOnlyHint: ToBoolPtr(true),
 			}),
 		),
-		func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			// We need to convert the toolsetGroup back to a map for JSON serialization
 
 			payload := []map[string]string{}
 
 			for name, ts := range toolsetGroup.Toolsets {
 				{
+					tools := ts.GetAvailableTools()
 					t := map[string]string{
 						"name":              name,
 						"description":       ts.Description,
 						"can_enable":        "true",
-						"currently_enabled": fmt.Sprintf("%t", ts.Enabled),
+						"currently_enabled": fmt.Sprintf("%t", toolsetEnabled(ctx, ts)),
+						"tool_count":        fmt.Sprintf("%d", len(tools)),
+						"read_only":         fmt.Sprintf("%t", readOnlyTools(tools)),
+						"approx_tokens":     fmt.Sprintf("%d", approximateTokens(tools)),
 					}
 					payload = append(payload, t)
 				}
@@ -93,6 +257,30 @@ func ListAvailableToolsets(toolsetGroup *toolsets.ToolsetGroup, t translations.T
 		}
 }
 
+// readOnlyTools reports whether none of the tools modify data on GitHub.
+func readOnlyTools(tools []server.ServerTool) bool {
+	for _, st := range tools {
+		if st.Tool.Annotations.ReadOnlyHint == nil || !*st.Tool.Annotations.ReadOnlyHint {
+			return false
+		}
+	}
+	return true
+}
+
+// approximateTokens estimates how many tokens the definitions of the tools take up in the
+// context of a model, at roughly four bytes of JSON per token.
+func approximateTokens(tools []server.ServerTool) int {
+	size := 0
+	for _, st := range tools {
+		definition, err := json.Marshal(st.Tool)
+		if err != nil {
+			continue
+		}
+		size += len(definition)
+	}
+	return (size + 3) / 4
+}
+
 func GetToolsetsTools(toolsetGroup *toolsets.ToolsetGroup, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_toolset_tools",
 			mcp.WithDescription(t("TOOL_GET_TOOLSET_TOOLS_DESCRIPTION", "Lists all the capabilities that are enabled with the specified toolset, use this to get clarity on whether enabling a toolset would help you to complete a task")),
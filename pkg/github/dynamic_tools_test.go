@@ -0,0 +1,252 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolsSessionStub is a client session that holds its own tools, like the SSE and streamable
+// HTTP sessions.
+type toolsSessionStub struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	tools         map[string]server.ServerTool
+}
+
+func newToolsSessionStub(id string) *toolsSessionStub {
+	return &toolsSessionStub{id: id, notifications: make(chan mcp.JSONRPCNotification, 10)}
+}
+
+func (s *toolsSessionStub) SessionID() string { return s.id }
+func (s *toolsSessionStub) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+func (s *toolsSessionStub) Initialize()                                    {}
+func (s *toolsSessionStub) Initialized() bool                              { return true }
+func (s *toolsSessionStub) GetSessionTools() map[string]server.ServerTool  { return s.tools }
+func (s *toolsSessionStub) SetSessionTools(t map[string]server.ServerTool) { s.tools = t }
+
+// serverToolNames returns the names of the tools the server lists to clients without tools of their own.
+func serverToolNames(t *testing.T, s *server.MCPServer) []string {
+	response, ok := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	result, ok := response.Result.(mcp.ListToolsResult)
+	require.True(t, ok)
+	names := make([]string, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func Test_EnableToolset(t *testing.T) {
+	newToolsetGroup := func() *toolsets.ToolsetGroup {
+		tsg := toolsets.NewToolsetGroup(false)
+		tsg.AddToolset(toolsets.NewToolset("actions", "GitHub Actions").
+			AddReadTools(toolsets.NewServerTool(mcp.NewTool("list_workflows",
+				mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: ToBoolPtr(true)})),
+				func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+					return mcp.NewToolResultText("workflows"), nil
+				})))
+		return tsg
+	}
+	listToolsets := func(t *testing.T, ctx context.Context, tsg *toolsets.ToolsetGroup) map[string]string {
+		_, handler := ListAvailableToolsets(tsg, translations.NullTranslationHelper)
+		result, err := handler(ctx, createMCPRequest(map[string]any{}))
+		require.NoError(t, err)
+		var toolsets []map[string]string
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &toolsets))
+		enabled := map[string]string{}
+		for _, ts := range toolsets {
+			enabled[ts["name"]] = ts["currently_enabled"]
+		}
+		return enabled
+	}
+
+	t.Run("sessions with their own tools", func(t *testing.T) {
+		tsg := newToolsetGroup()
+		s := server.NewMCPServer("test", "test", server.WithToolCapabilities(true))
+		_, handler := EnableToolset(s, tsg, NewSessionTools(), translations.NullTranslationHelper)
+
+		first, second := newToolsSessionStub("first"), newToolsSessionStub("second")
+		firstCtx := s.WithContext(context.Background(), first)
+		secondCtx := s.WithContext(context.Background(), second)
+
+		result, err := handler(firstCtx, createMCPRequest(map[string]any{"toolset": "actions"}))
+		require.NoError(t, err)
+		assert.Equal(t, "Toolset actions enabled", getTextResult(t, result).Text)
+
+		assert.False(t, tsg.Toolsets["actions"].Enabled, "the toolset should not be enabled globally")
+		assert.Contains(t, first.tools, "list_workflows")
+		assert.Empty(t, second.tools)
+		require.Len(t, first.notifications, 1)
+		assert.Equal(t, "notifications/tools/list_changed", (<-first.notifications).Method)
+		assert.Empty(t, second.notifications)
+
+		assert.Equal(t, "true", listToolsets(t, firstCtx, tsg)["actions"])
+		assert.Equal(t, "false", listToolsets(t, secondCtx, tsg)["actions"])
+
+		result, err = handler(firstCtx, createMCPRequest(map[string]any{"toolset": "actions"}))
+		require.NoError(t, err)
+		assert.Equal(t, "Toolset actions is already enabled", getTextResult(t, result).Text)
+	})
+
+	t.Run("clearing the session tools", func(t *testing.T) {
+		tsg := newToolsetGroup()
+		s := server.NewMCPServer("test", "test", server.WithToolCapabilities(true))
+		sessions := NewSessionTools()
+		_, handler := EnableToolset(s, tsg, sessions, translations.NullTranslationHelper)
+
+		session := newToolsSessionStub("session")
+		_, err := handler(s.WithContext(context.Background(), session), createMCPRequest(map[string]any{"toolset": "actions"}))
+		require.NoError(t, err)
+		require.Contains(t, session.tools, "list_workflows")
+
+		sessions.Clear()
+		assert.Empty(t, session.tools)
+		assert.Zero(t, sessions.len())
+	})
+
+	t.Run("sessions sharing the server tools", func(t *testing.T) {
+		tsg := newToolsetGroup()
+		s := server.NewMCPServer("test", "test", server.WithToolCapabilities(true))
+		_, handler := EnableToolset(s, tsg, NewSessionTools(), translations.NullTranslationHelper)
+
+		result, err := handler(context.Background(), createMCPRequest(map[string]any{"toolset": "actions"}))
+		require.NoError(t, err)
+		assert.Equal(t, "Toolset actions enabled", getTextResult(t, result).Text)
+		assert.True(t, tsg.Toolsets["actions"].Enabled)
+		assert.Equal(t, "true", listToolsets(t, context.Background(), tsg)["actions"])
+	})
+
+	t.Run("unknown toolset", func(t *testing.T) {
+		s := server.NewMCPServer("test", "test")
+		_, handler := EnableToolset(s, newToolsetGroup(), NewSessionTools(), translations.NullTranslationHelper)
+
+		result, err := handler(context.Background(), createMCPRequest(map[string]any{"toolset": "unknown"}))
+		require.NoError(t, err)
+		assert.Equal(t, "Toolset unknown not found", getErrorResult(t, result).Text)
+	})
+}
+
+func Test_DisableToolset(t *testing.T) {
+	newToolsetGroup := func() *toolsets.ToolsetGroup {
+		tsg := toolsets.NewToolsetGroup(false)
+		tsg.AddToolset(toolsets.NewToolset("actions", "GitHub Actions").
+			AddReadTools(toolsets.NewServerTool(mcp.NewTool("list_workflows",
+				mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: ToBoolPtr(true)})),
+				func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+					return mcp.NewToolResultText("workflows"), nil
+				})))
+		return tsg
+	}
+	request := createMCPRequest(map[string]any{"toolset": "actions"})
+
+	t.Run("sessions with their own tools", func(t *testing.T) {
+		tsg := newToolsetGroup()
+		s := server.NewMCPServer("test", "test", server.WithToolCapabilities(true))
+		sessions := NewSessionTools()
+		_, enable := EnableToolset(s, tsg, sessions, translations.NullTranslationHelper)
+		_, disable := DisableToolset(s, tsg, sessions, translations.NullTranslationHelper)
+
+		session := newToolsSessionStub("session")
+		ctx := s.WithContext(context.Background(), session)
+
+		result, err := disable(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, "Toolset actions is already disabled", getTextResult(t, result).Text)
+
+		_, err = enable(ctx, request)
+		require.NoError(t, err)
+		require.Contains(t, session.tools, "list_workflows")
+		<-session.notifications
+		assert.Equal(t, 1, sessions.len())
+
+		result, err = disable(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, "Toolset actions disabled", getTextResult(t, result).Text)
+		assert.NotContains(t, session.tools, "list_workflows")
+		require.Len(t, session.notifications, 1)
+		assert.Equal(t, "notifications/tools/list_changed", (<-session.notifications).Method)
+		assert.Zero(t, sessions.len(), "sessions without tools are forgotten")
+	})
+
+	t.Run("toolsets enabled for all sessions", func(t *testing.T) {
+		tsg := newToolsetGroup()
+		tsg.Toolsets["actions"].Enabled = true
+		s := server.NewMCPServer("test", "test", server.WithToolCapabilities(true))
+		_, disable := DisableToolset(s, tsg, NewSessionTools(), translations.NullTranslationHelper)
+
+		ctx := s.WithContext(context.Background(), newToolsSessionStub("session"))
+		result, err := disable(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, "Toolset actions is enabled for all sessions by the server configuration and cannot be disabled", getErrorResult(t, result).Text)
+		assert.True(t, tsg.Toolsets["actions"].Enabled)
+	})
+
+	t.Run("sessions sharing the server tools", func(t *testing.T) {
+		tsg := newToolsetGroup()
+		s := server.NewMCPServer("test", "test", server.WithToolCapabilities(true))
+		_, enable := EnableToolset(s, tsg, NewSessionTools(), translations.NullTranslationHelper)
+		_, disable := DisableToolset(s, tsg, NewSessionTools(), translations.NullTranslationHelper)
+
+		_, err := enable(context.Background(), request)
+		require.NoError(t, err)
+		assert.Contains(t, serverToolNames(t, s), "list_workflows")
+
+		result, err := disable(context.Background(), request)
+		require.NoError(t, err)
+		assert.Equal(t, "Toolset actions disabled", getTextResult(t, result).Text)
+		assert.False(t, tsg.Toolsets["actions"].Enabled)
+		assert.NotContains(t, serverToolNames(t, s), "list_workflows")
+	})
+}
+
+func Test_ListAvailableToolsets(t *testing.T) {
+	noop := func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(""), nil
+	}
+	tsg := toolsets.NewToolsetGroup(false)
+	tsg.AddToolset(toolsets.NewToolset("actions", "GitHub Actions").
+		AddReadTools(toolsets.NewServerTool(mcp.NewTool("list_workflows",
+			mcp.WithDescription("List workflows"),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: ToBoolPtr(true)})), noop)))
+	tsg.AddToolset(toolsets.NewToolset("issues", "GitHub Issues").
+		AddReadTools(toolsets.NewServerTool(mcp.NewTool("get_issue",
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: ToBoolPtr(true)})), noop)).
+		AddWriteTools(toolsets.NewServerTool(mcp.NewTool("create_issue",
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: ToBoolPtr(false)})), noop)))
+	tsg.Toolsets["issues"].Enabled = true
+
+	_, handler := ListAvailableToolsets(tsg, translations.NullTranslationHelper)
+	result, err := handler(context.Background(), createMCPRequest(map[string]any{}))
+	require.NoError(t, err)
+
+	var payload []map[string]string
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &payload))
+	byName := map[string]map[string]string{}
+	for _, ts := range payload {
+		byName[ts["name"]] = ts
+	}
+
+	definition, err := json.Marshal(tsg.Toolsets["actions"].GetAvailableTools()[0].Tool)
+	require.NoError(t, err)
+
+	assert.Equal(t, "false", byName["actions"]["currently_enabled"])
+	assert.Equal(t, "1", byName["actions"]["tool_count"])
+	assert.Equal(t, "true", byName["actions"]["read_only"])
+	assert.Equal(t, fmt.Sprintf("%d", (len(definition)+3)/4), byName["actions"]["approx_tokens"])
+
+	assert.Equal(t, "true", byName["issues"]["currently_enabled"])
+	assert.Equal(t, "2", byName["issues"]["tool_count"])
+	assert.Equal(t, "false", byName["issues"]["read_only"])
+}
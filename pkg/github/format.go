@@ -0,0 +1,316 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormatArgument is the tool argument that selects the format of a single result.
+const OutputFormatArgument = "output_format"
+
+// OutputFormat is the text format JSON tool results are rendered in.
+type OutputFormat string
+
+const (
+	// OutputCompactJSON is JSON without whitespace, as the tools produce it
+	OutputCompactJSON OutputFormat = "compact"
+	// OutputJSON is indented JSON
+	OutputJSON OutputFormat = "json"
+	// OutputYAML is YAML, which takes fewer tokens than JSON
+	OutputYAML OutputFormat = "yaml"
+	// OutputMarkdown renders lists of objects as Markdown tables and objects as Markdown lists
+	OutputMarkdown OutputFormat = "markdown"
+)
+
+var outputFormats = []OutputFormat{OutputCompactJSON, OutputJSON, OutputYAML, OutputMarkdown}
+
+// ParseOutputFormat returns the output format named s.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	format := OutputFormat(s)
+	if !slices.Contains(outputFormats, format) {
+		return "", fmt.Errorf("unknown output format %q, must be one of %q", s, outputFormats)
+	}
+	return format, nil
+}
+
+// Renderer renders the JSON results of all tools in the output format asked for by the call,
+// or in the default format of the server.
+type Renderer struct {
+	format OutputFormat
+}
+
+// NewRenderer returns a renderer with the given default format, compact JSON if it is empty.
+func NewRenderer(format OutputFormat) *Renderer {
+	if format == "" {
+		format = OutputCompactJSON
+	}
+	return &Renderer{format: format}
+}
+
+// ToolMiddleware renders the text contents of tool results that are JSON. Other text, such as
+// file contents and error messages, is returned as is.
+func (r *Renderer) ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		format := r.format
+		if arg, ok := request.GetArguments()[OutputFormatArgument]; ok && arg != "" {
+			name, ok := arg.(string)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("parameter %s is not of type string, is %T", OutputFormatArgument, arg)), nil
+			}
+			var err error
+			if format, err = ParseOutputFormat(name); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+
+		content := make([]mcp.Content, len(result.Content))
+		for i, c := range result.Content {
+			content[i] = c
+			text, ok := c.(mcp.TextContent)
+			if !ok || !isJSONDocument(text.Text) {
+				continue
+			}
+			if text.Text, err = Render([]byte(text.Text), format); err != nil {
+				return nil, fmt.Errorf("failed to render result as %s: %w", format, err)
+			}
+			content[i] = text
+		}
+		rendered := *result
+		rendered.Content = content
+		return &rendered, nil
+	}
+}
+
+// ToolFilter adds the output format argument to the listed tools.
+func (r *Renderer) ToolFilter(_ context.Context, tools []mcp.Tool) []mcp.Tool {
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		filtered = append(filtered, WithToolProperty(tool, OutputFormatArgument, map[string]any{
+			"type":        "string",
+			"description": fmt.Sprintf("Format of the result, defaults to %q. yaml takes fewer tokens, markdown renders lists as tables", r.format),
+			"enum":        outputFormats,
+		}))
+	}
+	return filtered
+}
+
+// isJSONDocument reports whether text is a JSON object or array.
+func isJSONDocument(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	return json.Valid([]byte(trimmed))
+}
+
+// Render returns the JSON data in the given format.
+func Render(data []byte, format OutputFormat) (string, error) {
+	var buf bytes.Buffer
+	switch format {
+	case OutputCompactJSON:
+		if err := json.Compact(&buf, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	case OutputJSON:
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	v, err := decodeJSON(data)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case OutputYAML:
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(yamlValue(v)); err != nil {
+			return "", err
+		}
+		if err := encoder.Close(); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	case OutputMarkdown:
+		return markdown(v), nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// yamlValue replaces the JSON numbers of v with integers, or with YAML number nodes keeping their
+// text for those that do not fit an int64, so that YAML encodes them as numbers rather than strings
+// without the precision they would lose as floats.
+func yamlValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, elem := range v {
+			v[k] = yamlValue(elem)
+		}
+		return v
+	case []any:
+		for i, elem := range v {
+			v[i] = yamlValue(elem)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	default:
+		return v
+	}
+}
+
+// markdown renders arrays of objects as tables, objects as lists of their fields with a table for
+// each field holding an array of objects, and other values as text.
+func markdown(v any) string {
+	switch v := v.(type) {
+	case []any:
+		if len(v) > 0 && allObjects(v) {
+			return markdownTable(v)
+		}
+		var b strings.Builder
+		for _, elem := range v {
+			fmt.Fprintf(&b, "- %s\n", markdownCell(elem))
+		}
+		return b.String()
+	case map[string]any:
+		var b strings.Builder
+		var tables []string
+		fields := flatten("", v)
+		for _, key := range slices.Sorted(maps.Keys(fields)) {
+			if list, ok := fields[key].([]any); ok && len(list) > 0 && allObjects(list) {
+				tables = append(tables, key)
+				continue
+			}
+			fmt.Fprintf(&b, "- **%s**: %s\n", key, markdownCell(fields[key]))
+		}
+		for _, key := range tables {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "### %s\n\n%s", key, markdownTable(fields[key].([]any)))
+		}
+		return b.String()
+	default:
+		return markdownCell(v) + "\n"
+	}
+}
+
+// markdownTable renders objects as the rows of a table, with a column for each of their fields.
+func markdownTable(rows []any) string {
+	flattened := make([]map[string]any, len(rows))
+	columns := make(map[string]bool)
+	for i, row := range rows {
+		flattened[i] = flatten("", row.(map[string]any))
+		for key := range flattened[i] {
+			columns[key] = true
+		}
+	}
+	keys := slices.Sorted(maps.Keys(columns))
+
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(keys, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(keys)) + "\n")
+	for _, row := range flattened {
+		cells := make([]string, len(keys))
+		for i, key := range keys {
+			cells[i] = markdownCell(row[key])
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return b.String()
+}
+
+// flatten returns the fields of obj with the fields of nested objects under dotted keys.
+func flatten(prefix string, obj map[string]any) map[string]any {
+	fields := make(map[string]any, len(obj))
+	for key, value := range obj {
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			maps.Copy(fields, flatten(prefix+key+".", nested))
+			continue
+		}
+		fields[prefix+key] = value
+	}
+	return fields
+}
+
+// markdownCell renders a value on a single line. Lists of values and of objects with a single
+// field are joined with commas, other objects are written as JSON.
+func markdownCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeMarkdownCell(v)
+	case []any:
+		parts := make([]string, len(v))
+		for i, elem := range v {
+			if obj, ok := elem.(map[string]any); ok && len(obj) == 1 {
+				for _, value := range obj {
+					elem = value
+				}
+			}
+			if _, ok := elem.(map[string]any); ok {
+				return jsonCell(v)
+			}
+			if _, ok := elem.([]any); ok {
+				return jsonCell(v)
+			}
+			parts[i] = markdownCell(elem)
+		}
+		return strings.Join(parts, ", ")
+	case map[string]any:
+		return jsonCell(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func jsonCell(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return escapeMarkdownCell(string(data))
+}
+
+// escapeMarkdownCell keeps text on one line and within its table cell.
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+func allObjects(list []any) bool {
+	for _, elem := range list {
+		if _, ok := elem.(map[string]any); !ok {
+			return false
+		}
+	}
+	return true
+}
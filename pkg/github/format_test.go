@@ -0,0 +1,137 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Render(t *testing.T) {
+	issues := `[{"number":42,"title":"Bug | crash","id":1234567890123,"user":{"login":"octocat"},"labels":[{"name":"bug"},{"name":"p1"}],"body":"line 1\nline 2","closed_at":null}]`
+
+	tests := []struct {
+		name     string
+		data     string
+		format   OutputFormat
+		expected string
+	}{
+		{
+			name:     "compact",
+			data:     "{\n  \"number\": 42\n}",
+			format:   OutputCompactJSON,
+			expected: `{"number":42}`,
+		},
+		{
+			name:     "json",
+			data:     `{"number":42,"labels":["bug"]}`,
+			format:   OutputJSON,
+			expected: "{\n  \"number\": 42,\n  \"labels\": [\n    \"bug\"\n  ]\n}",
+		},
+		{
+			name:     "yaml keeps large numbers",
+			data:     `{"id":1234567890123,"ratio":0.5,"title":"Bug","labels":["bug"]}`,
+			format:   OutputYAML,
+			expected: "id: 1234567890123\nlabels:\n  - bug\nratio: 0.5\ntitle: Bug\n",
+		},
+		{
+			name:     "yaml keeps numbers beyond int64 and float64",
+			data:     `{"id":18446744073709551615,"ratio":0.12345678901234567890123,"small":1e-400}`,
+			format:   OutputYAML,
+			expected: "id: 18446744073709551615\nratio: 0.12345678901234567890123\nsmall: 1e-400\n",
+		},
+		{
+			name:   "markdown table",
+			data:   issues,
+			format: OutputMarkdown,
+			expected: "| body | closed_at | id | labels | number | title | user.login |\n" +
+				"| --- | --- | --- | --- | --- | --- | --- |\n" +
+				"| line 1<br>line 2 |  | 1234567890123 | bug, p1 | 42 | Bug \\| crash | octocat |\n",
+		},
+		{
+			name:   "markdown object with a list",
+			data:   `{"total_count":1,"incomplete_results":false,"items":` + issues + `}`,
+			format: OutputMarkdown,
+			expected: "- **incomplete_results**: false\n" +
+				"- **total_count**: 1\n" +
+				"\n### items\n\n" +
+				"| body | closed_at | id | labels | number | title | user.login |\n" +
+				"| --- | --- | --- | --- | --- | --- | --- |\n" +
+				"| line 1<br>line 2 |  | 1234567890123 | bug, p1 | 42 | Bug \\| crash | octocat |\n",
+		},
+		{
+			name:     "markdown list of values",
+			data:     `["main","dev"]`,
+			format:   OutputMarkdown,
+			expected: "- main\n- dev\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rendered, err := Render([]byte(tc.data), tc.format)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rendered)
+		})
+	}
+}
+
+func Test_ParseOutputFormat(t *testing.T) {
+	format, err := ParseOutputFormat("yaml")
+	require.NoError(t, err)
+	assert.Equal(t, OutputYAML, format)
+
+	_, err = ParseOutputFormat("xml")
+	require.Error(t, err)
+}
+
+func Test_RendererToolMiddleware(t *testing.T) {
+	renderer := NewRenderer(OutputYAML)
+	handler := renderer.ToolMiddleware(func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetArguments()["fail"] == true {
+			return mcp.NewToolResultError(`{"message":"not found"}`), nil
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{
+			mcp.NewTextContent("successfully downloaded text file"),
+			mcp.NewTextContent(`{"number":42}`),
+		}}, nil
+	})
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		result, err := handler(context.Background(), createMCPRequest(args))
+		require.NoError(t, err)
+		return result
+	}
+
+	// JSON text is rendered in the default format, other text is left alone
+	result := call(nil)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "successfully downloaded text file", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, "number: 42\n", result.Content[1].(mcp.TextContent).Text)
+
+	// The format of the call overrides the default
+	result = call(map[string]any{OutputFormatArgument: "json"})
+	assert.Equal(t, "{\n  \"number\": 42\n}", result.Content[1].(mcp.TextContent).Text)
+
+	// Errors are returned as is
+	result = call(map[string]any{"fail": true, OutputFormatArgument: "markdown"})
+	require.True(t, result.IsError)
+	assert.Equal(t, `{"message":"not found"}`, getErrorResult(t, result).Text)
+
+	result = call(map[string]any{OutputFormatArgument: "xml"})
+	require.True(t, result.IsError)
+	assert.Contains(t, getErrorResult(t, result).Text, `unknown output format "xml"`)
+}
+
+func Test_RendererToolFilter(t *testing.T) {
+	tool := mcp.NewTool("get_me")
+	tools := NewRenderer("").ToolFilter(context.Background(), []mcp.Tool{tool})
+	require.Len(t, tools, 1)
+	property, ok := tools[0].InputSchema.Properties[OutputFormatArgument].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, outputFormats, property["enum"])
+	assert.Contains(t, property["description"], `defaults to "compact"`)
+	assert.NotContains(t, tool.InputSchema.Properties, OutputFormatArgument, "the registered tool is not changed")
+}
@@ -38,6 +38,7 @@ func GetIssue(getClient GetClientFn, t translations.TranslationHelperFunc) (tool
 				mcp.Required(),
 				mcp.Description("The number of the issue"),
 			),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -53,6 +54,11 @@ func GetIssue(getClient GetClientFn, t translations.TranslationHelperFunc) (tool
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			shape, err := issueShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -71,7 +77,7 @@ func GetIssue(getClient GetClientFn, t translations.TranslationHelperFunc) (tool
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get issue: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(issue)
+			r, err := shape.marshal(issue)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal issue: %w", err)
 			}
@@ -595,9 +601,10 @@ func SearchIssues(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				mcp.Enum("asc", "desc"),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			return searchHandler(ctx, getClient, request, "issue", "failed to search issues")
+			return searchHandler(ctx, getClient, request, issueSearchShape, "issue", "failed to search issues")
 		}
 }
 
@@ -763,6 +770,7 @@ func ListIssues(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 				mcp.Description("Filter by date (ISO 8601 timestamp)"),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -810,19 +818,24 @@ func ListIssues(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 				opts.Since = timestamp
 			}
 
-			if page, ok := request.GetArguments()["page"].(float64); ok {
-				opts.ListOptions.Page = int(page)
+			pagination, err := OptionalPaginationParams(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			if perPage, ok := request.GetArguments()["perPage"].(float64); ok {
-				opts.ListOptions.PerPage = int(perPage)
+			shape, err := issueShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
-			issues, resp, err := client.Issues.ListByRepo(ctx, owner, repo, opts)
+			issues, resp, pages, err := fetchPages(pagination, func(listOpts github.ListOptions) ([]*github.Issue, *github.Response, error) {
+				opts.ListOptions = listOpts
+				return client.Issues.ListByRepo(ctx, owner, repo, opts)
+			})
 			if err != nil {
 				return nil, fmt.Errorf("failed to list issues: %w", err)
 			}
@@ -836,12 +849,12 @@ func ListIssues(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 				return mcp.NewToolResultError(fmt.Sprintf("failed to list issues: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(issues)
+			r, err := shape.marshal(issues)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal issues: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -1012,6 +1025,7 @@ func GetIssueComments(getClient GetClientFn, t translations.TranslationHelperFun
 				mcp.Description("Issue number"),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -1031,18 +1045,18 @@ func GetIssueComments(getClient GetClientFn, t translations.TranslationHelperFun
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			opts := &github.IssueListCommentsOptions{
-				ListOptions: github.ListOptions{
-					Page:    pagination.Page,
-					PerPage: pagination.PerPage,
-				},
+			shape, err := issueCommentShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
-			comments, resp, err := client.Issues.ListComments(ctx, owner, repo, issueNumber, opts)
+			comments, resp, pages, err := fetchPages(pagination, func(opts github.ListOptions) ([]*github.IssueComment, *github.Response, error) {
+				return client.Issues.ListComments(ctx, owner, repo, issueNumber, &github.IssueListCommentsOptions{ListOptions: opts})
+			})
 			if err != nil {
 				return nil, fmt.Errorf("failed to get issue comments: %w", err)
 			}
@@ -1056,12 +1070,12 @@ func GetIssueComments(getClient GetClientFn, t translations.TranslationHelperFun
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get issue comments: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(comments)
+			r, err := shape.marshal(comments)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
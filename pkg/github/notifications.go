@@ -47,8 +47,14 @@ func ListNotifications(getClient GetClientFn, t translations.TranslationHelperFu
 				mcp.Description("Optional repository name. If provided with owner, only notifications for this repository are listed."),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			shape, err := notificationShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -87,10 +93,6 @@ func ListNotifications(getClient GetClientFn, t translations.TranslationHelperFu
 			opts := &github.NotificationListOptions{
 				All:           filter == FilterIncludeRead,
 				Participating: filter == FilterOnlyParticipating,
-				ListOptions: github.ListOptions{
-					Page:    paginationParams.Page,
-					PerPage: paginationParams.PerPage,
-				},
 			}
 
 			// Parse time parameters if provided
@@ -110,14 +112,13 @@ func ListNotifications(getClient GetClientFn, t translations.TranslationHelperFu
 				opts.Before = beforeTime
 			}
 
-			var notifications []*github.Notification
-			var resp *github.Response
-
-			if owner != "" && repo != "" {
-				notifications, resp, err = client.Activity.ListRepositoryNotifications(ctx, owner, repo, opts)
-			} else {
-				notifications, resp, err = client.Activity.ListNotifications(ctx, opts)
-			}
+			notifications, resp, pages, err := fetchPages(paginationParams, func(listOpts github.ListOptions) ([]*github.Notification, *github.Response, error) {
+				opts.ListOptions = listOpts
+				if owner != "" && repo != "" {
+					return client.Activity.ListRepositoryNotifications(ctx, owner, repo, opts)
+				}
+				return client.Activity.ListNotifications(ctx, opts)
+			})
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					"failed to list notifications",
@@ -136,12 +137,12 @@ func ListNotifications(getClient GetClientFn, t translations.TranslationHelperFu
 			}
 
 			// Marshal response to JSON
-			r, err := json.Marshal(notifications)
+			r, err := shape.marshal(notifications)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -303,8 +304,14 @@ func GetNotificationDetails(getClient GetClientFn, t translations.TranslationHel
 				mcp.Required(),
 				mcp.Description("The ID of the notification"),
 			),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			shape, err := notificationShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -333,7 +340,7 @@ func GetNotificationDetails(getClient GetClientFn, t translations.TranslationHel
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get notification details: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(thread)
+			r, err := shape.marshal(thread)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
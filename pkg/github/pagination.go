@@ -0,0 +1,137 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/v73/github"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxFetchAllResults is the most results a call with fetch_all returns, and its default max_results.
+const maxFetchAllResults = 1000
+
+// maxPageSize is the largest page the REST and GraphQL APIs return.
+const maxPageSize = 100
+
+// fetchedPages describes the pages fetched for a tool call.
+type fetchedPages struct {
+	fetchAll bool
+	// pages is the number of pages fetched
+	pages int
+	// maxResultsReached is set when there were more results than max_results
+	maxResultsReached bool
+}
+
+// fetchAllLimit returns the number of results fetch_all stops at for the max_results parameter.
+func fetchAllLimit(maxResults int) int {
+	if maxResults <= 0 || maxResults > maxFetchAllResults {
+		return maxFetchAllResults
+	}
+	return maxResults
+}
+
+// fetchPages calls list with the page of the pagination parameters. With fetch_all, it fetches the
+// following pages too, with the largest page size, until there are no more or max_results results
+// were fetched, and returns the results of all pages with the response of the last one.
+//
+// As the pages fetch_all fetches are larger, the page parameter is turned into an offset: the
+// results start with the first result of page, as if it had been fetched with perPage.
+func fetchPages[T any](p PaginationParams, list func(opts github.ListOptions) ([]T, *github.Response, error)) ([]T, *github.Response, fetchedPages, error) {
+	opts := github.ListOptions{Page: p.Page, PerPage: p.PerPage}
+	if !p.FetchAll {
+		results, resp, err := list(opts)
+		return results, resp, fetchedPages{pages: 1}, err
+	}
+
+	limit := fetchAllLimit(p.MaxResults)
+	offset := (max(p.Page, 1) - 1) * max(p.PerPage, 0)
+	opts = github.ListOptions{Page: offset/maxPageSize + 1, PerPage: maxPageSize}
+	skip := offset % maxPageSize
+	fetched := fetchedPages{fetchAll: true}
+	all := make([]T, 0)
+	for {
+		results, resp, err := list(opts)
+		if err != nil {
+			return nil, resp, fetched, err
+		}
+		fetched.pages++
+		// Only the first page starts before the offset
+		results = results[min(skip, len(results)):]
+		skip = 0
+		all = append(all, results...)
+		if len(all) >= limit {
+			fetched.maxResultsReached = len(all) > limit || resp.NextPage != 0
+			return all[:limit], resp, fetched, nil
+		}
+		if resp.NextPage == 0 {
+			return all, resp, fetched, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// fetchCursorPages calls query with the cursor of the pagination parameters and first results.
+// With fetch_all, it follows the end cursor of each page the same way fetchPages follows the
+// next page. The returned page info starts at the first page and ends at the last one.
+func fetchCursorPages[T any](p CursorPaginationParams, first int32, query func(first int32, after *string) ([]T, PageInfoFragment, error)) ([]T, PageInfoFragment, fetchedPages, error) {
+	var after *string
+	if p.After != "" {
+		after = &p.After
+	}
+	if !p.FetchAll {
+		results, pageInfo, err := query(first, after)
+		return results, pageInfo, fetchedPages{pages: 1}, err
+	}
+
+	limit := fetchAllLimit(p.MaxResults)
+	fetched := fetchedPages{fetchAll: true}
+	all := make([]T, 0)
+	var pageInfo PageInfoFragment
+	for {
+		// Pages are never larger than the results still missing, so none are dropped and the
+		// end cursor is where the next call carries on
+		results, page, err := query(int32(min(maxPageSize, limit-len(all))), after) //nolint:gosec // at most maxPageSize
+		if err != nil {
+			return nil, pageInfo, fetched, err
+		}
+		if fetched.pages == 0 {
+			pageInfo.HasPreviousPage = page.HasPreviousPage
+			pageInfo.StartCursor = page.StartCursor
+		}
+		pageInfo.HasNextPage = page.HasNextPage
+		pageInfo.EndCursor = page.EndCursor
+		fetched.pages++
+		all = append(all, results...)
+		if !page.HasNextPage {
+			return all, pageInfo, fetched, nil
+		}
+		if len(all) >= limit {
+			fetched.maxResultsReached = true
+			return all, pageInfo, fetched, nil
+		}
+		endCursor := string(page.EndCursor)
+		after = &endCursor
+	}
+}
+
+// result returns the marshalled results as the text of a tool result. With fetch_all, they are
+// wrapped with the number of pages fetched and whether there were more results than max_results.
+func (f fetchedPages) result(r []byte) (*mcp.CallToolResult, error) {
+	if !f.fetchAll {
+		return mcp.NewToolResultText(string(r)), nil
+	}
+	wrapped, err := json.Marshal(struct {
+		Results           json.RawMessage `json:"results"`
+		Pages             int             `json:"pages"`
+		MaxResultsReached bool            `json:"max_results_reached"`
+	}{
+		Results:           r,
+		Pages:             f.pages,
+		MaxResultsReached: f.maxResultsReached,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal results: %w", err)
+	}
+	return mcp.NewToolResultText(string(wrapped)), nil
+}
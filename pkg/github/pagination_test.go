@@ -0,0 +1,200 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v73/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listPages returns a list function over pages of the given sizes, recording the options of each call.
+func listPages(sizes []int, calls *[]github.ListOptions) func(opts github.ListOptions) ([]int, *github.Response, error) {
+	return func(opts github.ListOptions) ([]int, *github.Response, error) {
+		*calls = append(*calls, opts)
+		page := max(opts.Page, 1)
+		results := make([]int, sizes[page-1])
+		for i := range results {
+			results[i] = page*1000 + i
+		}
+		resp := &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}
+		if page < len(sizes) {
+			resp.NextPage = page + 1
+		}
+		return results, resp, nil
+	}
+}
+
+func Test_FetchPages(t *testing.T) {
+	tests := []struct {
+		name            string
+		pagination      PaginationParams
+		sizes           []int
+		expectedCalls   []github.ListOptions
+		expectedResults int
+		// expectedFirst is the first result, which is 1000 times its page plus its index
+		expectedFirst     int
+		maxResultsReached bool
+	}{
+		{
+			name:            "single page without fetch_all",
+			pagination:      PaginationParams{Page: 2, PerPage: 30},
+			sizes:           []int{30, 30, 30},
+			expectedCalls:   []github.ListOptions{{Page: 2, PerPage: 30}},
+			expectedResults: 30,
+			expectedFirst:   2000,
+		},
+		{
+			name:            "all pages",
+			pagination:      PaginationParams{Page: 1, PerPage: 30, FetchAll: true},
+			sizes:           []int{100, 100, 20},
+			expectedCalls:   []github.ListOptions{{Page: 1, PerPage: 100}, {Page: 2, PerPage: 100}, {Page: 3, PerPage: 100}},
+			expectedResults: 220,
+			expectedFirst:   1000,
+		},
+		{
+			name:              "stops at max_results",
+			pagination:        PaginationParams{Page: 1, FetchAll: true, MaxResults: 150},
+			sizes:             []int{100, 100, 20},
+			expectedCalls:     []github.ListOptions{{Page: 1, PerPage: 100}, {Page: 2, PerPage: 100}},
+			expectedResults:   150,
+			expectedFirst:     1000,
+			maxResultsReached: true,
+		},
+		{
+			name:            "page is an offset",
+			pagination:      PaginationParams{Page: 3, PerPage: 30, FetchAll: true},
+			sizes:           []int{100, 100, 20},
+			expectedCalls:   []github.ListOptions{{Page: 1, PerPage: 100}, {Page: 2, PerPage: 100}, {Page: 3, PerPage: 100}},
+			expectedResults: 160,
+			expectedFirst:   1060,
+		},
+		{
+			name:            "offset past the first pages",
+			pagination:      PaginationParams{Page: 5, PerPage: 50, FetchAll: true},
+			sizes:           []int{100, 100, 100},
+			expectedCalls:   []github.ListOptions{{Page: 3, PerPage: 100}},
+			expectedResults: 100,
+			expectedFirst:   3000,
+		},
+		{
+			name:            "exactly max_results",
+			pagination:      PaginationParams{Page: 1, FetchAll: true, MaxResults: 120},
+			sizes:           []int{100, 20},
+			expectedCalls:   []github.ListOptions{{Page: 1, PerPage: 100}, {Page: 2, PerPage: 100}},
+			expectedResults: 120,
+			expectedFirst:   1000,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []github.ListOptions
+			results, resp, pages, err := fetchPages(tc.pagination, listPages(tc.sizes, &calls))
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+			assert.Equal(t, tc.expectedCalls, calls)
+			assert.Len(t, results, tc.expectedResults)
+			assert.Equal(t, tc.expectedFirst, results[0])
+			assert.Equal(t, tc.pagination.FetchAll, pages.fetchAll)
+			assert.Equal(t, len(calls), pages.pages)
+			assert.Equal(t, tc.maxResultsReached, pages.maxResultsReached)
+		})
+	}
+}
+
+func Test_FetchCursorPages(t *testing.T) {
+	type call struct {
+		first int32
+		after string
+	}
+	var calls []call
+	// Three pages of comments, ending at cursors c1, c2 and c3
+	query := func(first int32, after *string) ([]string, PageInfoFragment, error) {
+		c := call{first: first}
+		if after != nil {
+			c.after = *after
+		}
+		calls = append(calls, c)
+		page := len(calls)
+		results := make([]string, first)
+		return results, PageInfoFragment{
+			HasNextPage:     page < 3,
+			HasPreviousPage: page > 1,
+			StartCursor:     githubv4.String("s" + strconv.Itoa(page)),
+			EndCursor:       githubv4.String("c" + strconv.Itoa(page)),
+		}, nil
+	}
+
+	results, pageInfo, pages, err := fetchCursorPages(CursorPaginationParams{PerPage: 10, FetchAll: true, MaxResults: 250}, 10, query)
+	require.NoError(t, err)
+	assert.Equal(t, []call{{first: 100}, {first: 100, after: "c1"}, {first: 50, after: "c2"}}, calls)
+	assert.Len(t, results, 250)
+	assert.Equal(t, PageInfoFragment{HasNextPage: false, StartCursor: "s1", EndCursor: "c3"}, pageInfo)
+	assert.Equal(t, fetchedPages{fetchAll: true, pages: 3}, pages)
+
+	calls = nil
+	results, pageInfo, pages, err = fetchCursorPages(CursorPaginationParams{PerPage: 10, After: "c1", FetchAll: true, MaxResults: 100}, 10, query)
+	require.NoError(t, err)
+	assert.Equal(t, []call{{first: 100, after: "c1"}}, calls)
+	assert.Len(t, results, 100)
+	assert.True(t, pageInfo.HasNextPage)
+	assert.Equal(t, fetchedPages{fetchAll: true, pages: 1, maxResultsReached: true}, pages)
+
+	calls = nil
+	results, _, pages, err = fetchCursorPages(CursorPaginationParams{PerPage: 10}, 10, query)
+	require.NoError(t, err)
+	assert.Equal(t, []call{{first: 10}}, calls)
+	assert.Len(t, results, 10)
+	assert.False(t, pages.fetchAll)
+}
+
+func Test_ListBranchesFetchAll(t *testing.T) {
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposBranchesByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+				var branches []*github.Branch
+				switch r.URL.Query().Get("page") {
+				case "1":
+					w.Header().Set("Link", `<https://api.github.com/repos/owner/repo/branches?page=2&per_page=100>; rel="next"`)
+					branches = []*github.Branch{{Name: github.Ptr("main")}, {Name: github.Ptr("dev")}}
+				case "2":
+					branches = []*github.Branch{{Name: github.Ptr("release")}}
+				default:
+					t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+				}
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(branches)
+			}),
+		),
+	)
+	_, handler := ListBranches(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+
+	result, err := handler(context.Background(), createMCPRequest(map[string]any{
+		"owner":     "owner",
+		"repo":      "repo",
+		"fetch_all": true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var response struct {
+		Results           []*github.Branch `json:"results"`
+		Pages             int              `json:"pages"`
+		MaxResultsReached bool             `json:"max_results_reached"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+	require.Len(t, response.Results, 3)
+	assert.Equal(t, "release", response.Results[2].GetName())
+	assert.Equal(t, 2, response.Pages)
+	assert.False(t, response.MaxResultsReached)
+}
@@ -0,0 +1,223 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// responseShape is the minimal projection of a tool result, which keeps the fields a model needs
+// to reason about an object and drops the API URLs, node IDs and nested objects it rarely looks at.
+type responseShape struct {
+	// fields are the JSON paths that are kept, with dots between the names of nested fields.
+	// They apply to each element of arrays, so "labels.name" keeps the names of all labels.
+	fields []string
+
+	// items is the dotted path of the array the fields apply to when the result wraps it, like the
+	// "items" of search results. The other fields of the wrapper are kept.
+	items string
+
+	// verbose keeps the whole result
+	verbose bool
+}
+
+var (
+	issueShape = responseShape{fields: []string{
+		"number", "title", "state", "state_reason", "body", "user.login", "labels.name", "assignees.login",
+		"milestone.title", "comments", "html_url", "created_at", "updated_at", "closed_at", "pull_request.html_url",
+	}}
+	// issueSearchShape also fits pull requests, which the search API returns as issues
+	issueSearchShape = responseShape{fields: issueShape.fields, items: "items"}
+
+	issueCommentShape = responseShape{fields: []string{
+		"id", "body", "user.login", "author_association", "html_url", "created_at", "updated_at",
+	}}
+
+	pullRequestShape = responseShape{fields: []string{
+		"number", "title", "state", "draft", "merged", "mergeable", "mergeable_state", "body", "user.login",
+		"labels.name", "assignees.login", "requested_reviewers.login", "head.ref", "head.sha", "base.ref",
+		"commits", "additions", "deletions", "changed_files", "comments", "review_comments", "html_url",
+		"created_at", "updated_at", "closed_at", "merged_at",
+	}}
+
+	pullRequestFileShape = responseShape{fields: []string{
+		"filename", "previous_filename", "status", "additions", "deletions", "changes", "patch",
+	}}
+
+	reviewCommentShape = responseShape{fields: []string{
+		"id", "in_reply_to_id", "path", "line", "start_line", "side", "body", "user.login", "html_url",
+		"created_at", "updated_at",
+	}}
+
+	reviewShape = responseShape{fields: []string{
+		"id", "state", "body", "user.login", "commit_id", "html_url", "submitted_at",
+	}}
+
+	commitShape = responseShape{fields: []string{
+		"sha", "html_url", "commit.message", "commit.author.name", "commit.author.email", "commit.author.date",
+		"author.login", "parents.sha", "stats", "files.filename", "files.status", "files.additions",
+		"files.deletions", "files.changes", "files.patch",
+	}}
+
+	workflowRunShape = responseShape{fields: []string{
+		"id", "name", "display_title", "workflow_id", "run_number", "run_attempt", "event", "status",
+		"conclusion", "head_branch", "head_sha", "actor.login", "html_url", "created_at", "updated_at",
+		"run_started_at",
+	}}
+	workflowRunListShape = responseShape{fields: workflowRunShape.fields, items: "workflow_runs"}
+
+	workflowJobListShape = responseShape{fields: []string{
+		"id", "run_id", "name", "status", "conclusion", "head_branch", "head_sha", "html_url", "started_at",
+		"completed_at", "steps.name", "steps.number", "steps.status", "steps.conclusion",
+	}, items: "jobs.jobs"}
+
+	codeScanningAlertShape = responseShape{fields: []string{
+		"number", "state", "rule.id", "rule.name", "rule.severity", "rule.security_severity_level",
+		"rule.description", "tool.name", "most_recent_instance.ref", "most_recent_instance.location",
+		"most_recent_instance.message.text", "dismissed_reason", "html_url", "created_at", "fixed_at",
+	}}
+
+	secretScanningAlertShape = responseShape{fields: []string{
+		"number", "state", "secret_type", "secret_type_display_name", "validity", "resolution",
+		"push_protection_bypassed", "html_url", "created_at", "resolved_at",
+	}}
+
+	dependabotAlertShape = responseShape{fields: []string{
+		"number", "state", "dependency.package.ecosystem", "dependency.package.name", "dependency.manifest_path",
+		"dependency.scope", "security_advisory.ghsa_id", "security_advisory.cve_id", "security_advisory.summary",
+		"security_advisory.severity", "security_vulnerability.vulnerable_version_range",
+		"security_vulnerability.first_patched_version.identifier", "dismissed_reason", "html_url", "created_at",
+		"fixed_at",
+	}}
+
+	notificationShape = responseShape{fields: []string{
+		"id", "reason", "unread", "updated_at", "last_read_at", "subject.title", "subject.type", "subject.url",
+		"subject.latest_comment_url", "repository.full_name",
+	}}
+)
+
+// WithResponseShape adds the parameters to choose the fields of the result to a tool.
+func WithResponseShape() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithArray("fields",
+			mcp.Description("Fields to return instead of the default minimal set, with dots for nested fields, e.g. [\"number\", \"user.login\", \"labels.name\"]"),
+			mcp.Items(map[string]any{
+				"type": "string",
+			}),
+		)(tool)
+
+		mcp.WithBoolean("verbose",
+			mcp.Description("Return the full API response instead of the minimal set of fields"),
+		)(tool)
+	}
+}
+
+// forRequest returns the shape asked for by the fields and verbose parameters of the request.
+func (s responseShape) forRequest(r mcp.CallToolRequest) (responseShape, error) {
+	verbose, err := OptionalParam[bool](r, "verbose")
+	if err != nil {
+		return responseShape{}, err
+	}
+	fields, err := OptionalStringArrayParam(r, "fields")
+	if err != nil {
+		return responseShape{}, err
+	}
+	if verbose {
+		s.verbose = true
+	} else if len(fields) > 0 {
+		s.fields = fields
+	}
+	return s, nil
+}
+
+// marshal returns the JSON of v with only the fields of the shape.
+func (s responseShape) marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || s.verbose {
+		return data, err
+	}
+
+	decoded, err := decodeJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	var items []string
+	if s.items != "" {
+		items = strings.Split(s.items, ".")
+	}
+	return json.Marshal(projectItems(decoded, items, newFieldTree(s.fields)))
+}
+
+// fieldTree holds the fields to keep by name, with the nested fields to keep of each. A nil
+// subtree keeps the whole field.
+type fieldTree map[string]fieldTree
+
+func newFieldTree(fields []string) fieldTree {
+	tree := fieldTree{}
+	for _, field := range fields {
+		node := tree
+		names := strings.Split(field, ".")
+		for i, name := range names {
+			sub, seen := node[name]
+			if seen && sub == nil {
+				// The whole field is already kept
+				break
+			}
+			if i == len(names)-1 {
+				node[name] = nil
+				break
+			}
+			if sub == nil {
+				sub = fieldTree{}
+				node[name] = sub
+			}
+			node = sub
+		}
+	}
+	return tree
+}
+
+// projectItems projects the array found at the path of names, keeping everything around it.
+func projectItems(v any, path []string, tree fieldTree) any {
+	if len(path) == 0 {
+		return project(v, tree)
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	if inner, ok := obj[path[0]]; ok {
+		obj[path[0]] = projectItems(inner, path[1:], tree)
+	}
+	return obj
+}
+
+// project keeps the fields of the tree in objects and in each object of arrays.
+func project(v any, tree fieldTree) any {
+	switch v := v.(type) {
+	case []any:
+		projected := make([]any, len(v))
+		for i, elem := range v {
+			projected[i] = project(elem, tree)
+		}
+		return projected
+	case map[string]any:
+		projected := make(map[string]any, len(tree))
+		for name, sub := range tree {
+			value, ok := v[name]
+			if !ok {
+				continue
+			}
+			if sub == nil {
+				projected[name] = value
+			} else {
+				projected[name] = project(value, sub)
+			}
+		}
+		return projected
+	default:
+		return v
+	}
+}
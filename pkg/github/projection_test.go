@@ -0,0 +1,97 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v73/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResponseShape(t *testing.T) {
+	issue := &github.Issue{
+		Number:  github.Ptr(42),
+		Title:   github.Ptr("Bug"),
+		State:   github.Ptr("open"),
+		HTMLURL: github.Ptr("https://github.com/owner/repo/issues/42"),
+		NodeID:  github.Ptr("I_42"),
+		User:    &github.User{Login: github.Ptr("octocat"), ID: github.Ptr(int64(1))},
+		Labels: []*github.Label{
+			{Name: github.Ptr("bug"), Color: github.Ptr("red")},
+			{Name: github.Ptr("p1"), Color: github.Ptr("blue")},
+		},
+	}
+	shape := responseShape{fields: []string{"number", "title", "user.login", "labels.name", "milestone.title"}}
+
+	tests := []struct {
+		name     string
+		shape    responseShape
+		args     map[string]any
+		value    any
+		expected string
+	}{
+		{
+			name:     "default fields",
+			shape:    shape,
+			value:    issue,
+			expected: `{"labels":[{"name":"bug"},{"name":"p1"}],"number":42,"title":"Bug","user":{"login":"octocat"}}`,
+		},
+		{
+			name:     "fields argument",
+			shape:    shape,
+			args:     map[string]any{"fields": []any{"state", "user"}},
+			value:    issue,
+			expected: `{"state":"open","user":{"id":1,"login":"octocat"}}`,
+		},
+		{
+			name:     "whole field wins over nested field",
+			shape:    shape,
+			args:     map[string]any{"fields": []any{"user.login", "user", "labels.color"}},
+			value:    issue,
+			expected: `{"labels":[{"color":"red"},{"color":"blue"}],"user":{"id":1,"login":"octocat"}}`,
+		},
+		{
+			name:     "items of a wrapper",
+			shape:    responseShape{fields: []string{"number"}, items: "items"},
+			value:    &github.IssuesSearchResult{Total: github.Ptr(1), Issues: []*github.Issue{issue}},
+			expected: `{"items":[{"number":42}],"total_count":1}`,
+		},
+		{
+			name:     "nested items",
+			shape:    responseShape{fields: []string{"id"}, items: "jobs.jobs"},
+			value:    map[string]any{"jobs": map[string]any{"total_count": 1, "jobs": []any{map[string]any{"id": 7, "name": "build"}}}, "tip": "x"},
+			expected: `{"jobs":{"jobs":[{"id":7}],"total_count":1},"tip":"x"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := tc.shape.forRequest(createMCPRequest(tc.args))
+			require.NoError(t, err)
+			r, err := s.marshal(tc.value)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(r))
+		})
+	}
+
+	t.Run("large numbers are kept", func(t *testing.T) {
+		job := &github.WorkflowJob{ID: github.Ptr(int64(9007199254740993)), Name: github.Ptr("build")}
+		r, err := responseShape{fields: []string{"id"}}.marshal(job)
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":9007199254740993}`, string(r))
+	})
+
+	t.Run("verbose", func(t *testing.T) {
+		s, err := shape.forRequest(createMCPRequest(map[string]any{"verbose": true, "fields": []any{"number"}}))
+		require.NoError(t, err)
+		r, err := s.marshal(issue)
+		require.NoError(t, err)
+		assert.Contains(t, string(r), `"node_id":"I_42"`)
+		assert.Contains(t, string(r), `"color":"red"`)
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		_, err := shape.forRequest(createMCPRequest(map[string]any{"fields": "number"}))
+		require.Error(t, err)
+	})
+}
@@ -37,6 +37,7 @@ func GetPullRequest(getClient GetClientFn, t translations.TranslationHelperFunc)
 				mcp.Required(),
 				mcp.Description("Pull request number"),
 			),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -52,6 +53,11 @@ func GetPullRequest(getClient GetClientFn, t translations.TranslationHelperFunc)
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			shape, err := pullRequestShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -74,7 +80,7 @@ func GetPullRequest(getClient GetClientFn, t translations.TranslationHelperFunc)
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get pull request: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(pr)
+			r, err := shape.marshal(pr)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -362,6 +368,7 @@ func ListPullRequests(getClient GetClientFn, t translations.TranslationHelperFun
 				mcp.Enum("asc", "desc"),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -402,17 +409,21 @@ func ListPullRequests(getClient GetClientFn, t translations.TranslationHelperFun
 				Base:      base,
 				Sort:      sort,
 				Direction: direction,
-				ListOptions: github.ListOptions{
-					PerPage: pagination.PerPage,
-					Page:    pagination.Page,
-				},
+			}
+
+			shape, err := pullRequestShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
-			prs, resp, err := client.PullRequests.List(ctx, owner, repo, opts)
+			prs, resp, pages, err := fetchPages(pagination, func(listOpts github.ListOptions) ([]*github.PullRequest, *github.Response, error) {
+				opts.ListOptions = listOpts
+				return client.PullRequests.List(ctx, owner, repo, opts)
+			})
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					"failed to list pull requests",
@@ -430,12 +441,12 @@ func ListPullRequests(getClient GetClientFn, t translations.TranslationHelperFun
 				return mcp.NewToolResultError(fmt.Sprintf("failed to list pull requests: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(prs)
+			r, err := shape.marshal(prs)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -571,9 +582,10 @@ func SearchPullRequests(getClient GetClientFn, t translations.TranslationHelperF
 				mcp.Enum("asc", "desc"),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			return searchHandler(ctx, getClient, request, "pr", "failed to search pull requests")
+			return searchHandler(ctx, getClient, request, issueSearchShape, "pr", "failed to search pull requests")
 		}
 }
 
@@ -598,6 +610,7 @@ func GetPullRequestFiles(getClient GetClientFn, t translations.TranslationHelper
 				mcp.Description("Pull request number"),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -617,15 +630,18 @@ func GetPullRequestFiles(getClient GetClientFn, t translations.TranslationHelper
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			shape, err := pullRequestFileShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
-			opts := &github.ListOptions{
-				PerPage: pagination.PerPage,
-				Page:    pagination.Page,
-			}
-			files, resp, err := client.PullRequests.ListFiles(ctx, owner, repo, pullNumber, opts)
+			files, resp, pages, err := fetchPages(pagination, func(opts github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+				return client.PullRequests.ListFiles(ctx, owner, repo, pullNumber, &opts)
+			})
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					"failed to get pull request files",
@@ -643,12 +659,12 @@ func GetPullRequestFiles(getClient GetClientFn, t translations.TranslationHelper
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get pull request files: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(files)
+			r, err := shape.marshal(files)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -839,6 +855,7 @@ func GetPullRequestComments(getClient GetClientFn, t translations.TranslationHel
 				mcp.Required(),
 				mcp.Description("Pull request number"),
 			),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -860,6 +877,11 @@ func GetPullRequestComments(getClient GetClientFn, t translations.TranslationHel
 				},
 			}
 
+			shape, err := reviewCommentShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -882,7 +904,7 @@ func GetPullRequestComments(getClient GetClientFn, t translations.TranslationHel
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get pull request comments: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(comments)
+			r, err := shape.marshal(comments)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -911,6 +933,7 @@ func GetPullRequestReviews(getClient GetClientFn, t translations.TranslationHelp
 				mcp.Required(),
 				mcp.Description("Pull request number"),
 			),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -926,6 +949,11 @@ func GetPullRequestReviews(getClient GetClientFn, t translations.TranslationHelp
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			shape, err := reviewShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -948,7 +976,7 @@ func GetPullRequestReviews(getClient GetClientFn, t translations.TranslationHelp
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get pull request reviews: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(reviews)
+			r, err := shape.marshal(reviews)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
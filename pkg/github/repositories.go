@@ -38,6 +38,7 @@ func GetCommit(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 				mcp.Description("Commit SHA, branch name, or tag name"),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -57,16 +58,25 @@ func GetCommit(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			opts := &github.ListOptions{
-				Page:    pagination.Page,
-				PerPage: pagination.PerPage,
+			shape, err := commitShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
-			commit, resp, err := client.Repositories.GetCommit(ctx, owner, repo, sha, opts)
+			// The files of the commit are paginated, so fetch_all merges them into the commit
+			var commit *github.RepositoryCommit
+			files, resp, pages, err := fetchPages(pagination, func(opts github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+				page, resp, err := client.Repositories.GetCommit(ctx, owner, repo, sha, &opts)
+				if err != nil {
+					return nil, resp, err
+				}
+				commit = page
+				return page.Files, resp, nil
+			})
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					fmt.Sprintf("failed to get commit: %s", sha),
@@ -83,13 +93,14 @@ func GetCommit(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 				}
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get commit: %s", string(body))), nil
 			}
+			commit.Files = files
 
-			r, err := json.Marshal(commit)
+			r, err := shape.marshal(commit)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -116,6 +127,7 @@ func ListCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 				mcp.Description("Author username or email address to filter commits by"),
 			),
 			WithPagination(),
+			WithResponseShape(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -139,24 +151,27 @@ func ListCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 			// Set default perPage to 30 if not provided
-			perPage := pagination.PerPage
-			if perPage == 0 {
-				perPage = 30
+			if pagination.PerPage == 0 {
+				pagination.PerPage = 30
 			}
 			opts := &github.CommitsListOptions{
 				SHA:    sha,
 				Author: author,
-				ListOptions: github.ListOptions{
-					Page:    pagination.Page,
-					PerPage: perPage,
-				},
+			}
+
+			shape, err := commitShape.forRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
-			commits, resp, err := client.Repositories.ListCommits(ctx, owner, repo, opts)
+			commits, resp, pages, err := fetchPages(pagination, func(listOpts github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+				opts.ListOptions = listOpts
+				return client.Repositories.ListCommits(ctx, owner, repo, opts)
+			})
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					fmt.Sprintf("failed to list commits: %s", sha),
@@ -174,12 +189,12 @@ func ListCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 				return mcp.NewToolResultError(fmt.Sprintf("failed to list commits: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(commits)
+			r, err := shape.marshal(commits)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -215,19 +230,14 @@ func ListBranches(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			opts := &github.BranchListOptions{
-				ListOptions: github.ListOptions{
-					Page:    pagination.Page,
-					PerPage: pagination.PerPage,
-				},
-			}
-
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
-			branches, resp, err := client.Repositories.ListBranches(ctx, owner, repo, opts)
+			branches, resp, pages, err := fetchPages(pagination, func(opts github.ListOptions) ([]*github.Branch, *github.Response, error) {
+				return client.Repositories.ListBranches(ctx, owner, repo, &github.BranchListOptions{ListOptions: opts})
+			})
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					"failed to list branches",
@@ -250,7 +260,7 @@ func ListBranches(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -1197,17 +1207,14 @@ func ListTags(getClient GetClientFn, t translations.TranslationHelperFunc) (tool
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			opts := &github.ListOptions{
-				Page:    pagination.Page,
-				PerPage: pagination.PerPage,
-			}
-
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
-			tags, resp, err := client.Repositories.ListTags(ctx, owner, repo, opts)
+			tags, resp, pages, err := fetchPages(pagination, func(opts github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
+				return client.Repositories.ListTags(ctx, owner, repo, &opts)
+			})
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					"failed to list tags",
@@ -1230,7 +1237,7 @@ func ListTags(getClient GetClientFn, t translations.TranslationHelperFunc) (tool
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -37,18 +37,19 @@ func SearchRepositories(getClient GetClientFn, t translations.TranslationHelperF
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			opts := &github.SearchOptions{
-				ListOptions: github.ListOptions{
-					Page:    pagination.Page,
-					PerPage: pagination.PerPage,
-				},
-			}
-
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
-			result, resp, err := client.Search.Repositories(ctx, query, opts)
+			var result *github.RepositoriesSearchResult
+			repositories, resp, pages, err := fetchPages(pagination, func(opts github.ListOptions) ([]*github.Repository, *github.Response, error) {
+				page, resp, err := client.Search.Repositories(ctx, query, &github.SearchOptions{ListOptions: opts})
+				if err != nil {
+					return nil, resp, err
+				}
+				result = page
+				return page.Repositories, resp, nil
+			})
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					fmt.Sprintf("failed to search repositories with query '%s'", query),
@@ -65,13 +66,14 @@ func SearchRepositories(getClient GetClientFn, t translations.TranslationHelperF
 				}
 				return mcp.NewToolResultError(fmt.Sprintf("failed to search repositories: %s", string(body))), nil
 			}
+			result.Repositories = repositories
 
 			r, err := json.Marshal(result)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -117,10 +119,6 @@ func SearchCode(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 			opts := &github.SearchOptions{
 				Sort:  sort,
 				Order: order,
-				ListOptions: github.ListOptions{
-					PerPage: pagination.PerPage,
-					Page:    pagination.Page,
-				},
 			}
 
 			client, err := getClient(ctx)
@@ -128,7 +126,16 @@ func SearchCode(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
-			result, resp, err := client.Search.Code(ctx, query, opts)
+			var result *github.CodeSearchResult
+			codeResults, resp, pages, err := fetchPages(pagination, func(listOpts github.ListOptions) ([]*github.CodeResult, *github.Response, error) {
+				opts.ListOptions = listOpts
+				page, resp, err := client.Search.Code(ctx, query, opts)
+				if err != nil {
+					return nil, resp, err
+				}
+				result = page
+				return page.CodeResults, resp, nil
+			})
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					fmt.Sprintf("failed to search code with query '%s'", query),
@@ -145,13 +152,14 @@ func SearchCode(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 				}
 				return mcp.NewToolResultError(fmt.Sprintf("failed to search code: %s", string(body))), nil
 			}
+			result.CodeResults = codeResults
 
 			r, err := json.Marshal(result)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return pages.result(r)
 		}
 }
 
@@ -192,10 +200,6 @@ func userOrOrgHandler(accountType string, getClient GetClientFn) server.ToolHand
 		opts := &github.SearchOptions{
 			Sort:  sort,
 			Order: order,
-			ListOptions: github.ListOptions{
-				PerPage: pagination.PerPage,
-				Page:    pagination.Page,
-			},
 		}
 
 		client, err := getClient(ctx)
@@ -204,7 +208,16 @@ func userOrOrgHandler(accountType string, getClient GetClientFn) server.ToolHand
 		}
 
 		searchQuery := "type:" + accountType + " " + query
-		result, resp, err := client.Search.Users(ctx, searchQuery, opts)
+		var result *github.UsersSearchResult
+		users, resp, pages, err := fetchPages(pagination, func(listOpts github.ListOptions) ([]*github.User, *github.Response, error) {
+			opts.ListOptions = listOpts
+			page, resp, err := client.Search.Users(ctx, searchQuery, opts)
+			if err != nil {
+				return nil, resp, err
+			}
+			result = page
+			return page.Users, resp, nil
+		})
 		if err != nil {
 			return ghErrors.NewGitHubAPIErrorResponse(ctx,
 				fmt.Sprintf("failed to search %ss with query '%s'", accountType, query),
@@ -222,9 +235,9 @@ func userOrOrgHandler(accountType string, getClient GetClientFn) server.ToolHand
 			return mcp.NewToolResultError(fmt.Sprintf("failed to search %ss: %s", accountType, string(body))), nil
 		}
 
-		minimalUsers := make([]MinimalUser, 0, len(result.Users))
+		minimalUsers := make([]MinimalUser, 0, len(users))
 
-		for _, user := range result.Users {
+		for _, user := range users {
 			if user.Login != nil {
 				mu := MinimalUser{
 					Login:      user.GetLogin(),
@@ -251,7 +264,7 @@ func userOrOrgHandler(accountType string, getClient GetClientFn) server.ToolHand
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal response: %w", err)
 		}
-		return mcp.NewToolResultText(string(r)), nil
+		return pages.result(r)
 	}
 }
 
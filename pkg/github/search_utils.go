@@ -2,7 +2,6 @@ package github
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,6 +14,7 @@ func searchHandler(
 	ctx context.Context,
 	getClient GetClientFn,
 	request mcp.CallToolRequest,
+	shape responseShape,
 	searchType string,
 	errorPrefix string,
 ) (*mcp.CallToolResult, error) {
@@ -50,26 +50,36 @@ func searchHandler(
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	shape, err = shape.forRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	opts := &github.SearchOptions{
 		// Default to "created" if no sort is provided, as it's a common use case.
 		Sort:  sort,
 		Order: order,
-		ListOptions: github.ListOptions{
-			Page:    pagination.Page,
-			PerPage: pagination.PerPage,
-		},
 	}
 
 	client, err := getClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to get GitHub client: %w", errorPrefix, err)
 	}
-	result, resp, err := client.Search.Issues(ctx, query, opts)
+	var result *github.IssuesSearchResult
+	issues, resp, pages, err := fetchPages(pagination, func(listOpts github.ListOptions) ([]*github.Issue, *github.Response, error) {
+		opts.ListOptions = listOpts
+		page, resp, err := client.Search.Issues(ctx, query, opts)
+		if err != nil {
+			return nil, resp, err
+		}
+		result = page
+		return page.Issues, resp, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", errorPrefix, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	result.Issues = issues
 
 	if resp.StatusCode != http.StatusOK {
 		body, err := io.ReadAll(resp.Body)
@@ -79,10 +89,10 @@ func searchHandler(
 		return mcp.NewToolResultError(fmt.Sprintf("%s: %s", errorPrefix, string(body))), nil
 	}
 
-	r, err := json.Marshal(result)
+	r, err := shape.marshal(result)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to marshal response: %w", errorPrefix, err)
 	}
 
-	return mcp.NewToolResultText(string(r)), nil
+	return pages.result(r)
 }
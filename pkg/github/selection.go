@@ -0,0 +1,576 @@
+package github
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// SelectArgument is the tool argument with the path of the parts of a JSON result to return.
+const SelectArgument = "select"
+
+const (
+	// maxSelectLength is the longest select path accepted
+	maxSelectLength = 512
+	// maxSelectSteps is the most steps a select path has, the fields of its filters included
+	maxSelectSteps = 32
+	// maxSelectedValues is the most values a step of a select path selects
+	maxSelectedValues = 10000
+)
+
+// SelectToolMiddleware returns the parts of the JSON results of read tools selected by the select
+// argument of the call. Paths are checked before the tool is called.
+func SelectToolMiddleware(isWrite func(tool string) bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			arg, ok := request.GetArguments()[SelectArgument]
+			if !ok || arg == "" || isWrite(request.Params.Name) {
+				return next(ctx, request)
+			}
+			s, ok := arg.(string)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("parameter %s is not of type string, is %T", SelectArgument, arg)), nil
+			}
+			path, err := parseSelectPath(s)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError {
+				return result, err
+			}
+
+			selected := false
+			content := make([]mcp.Content, len(result.Content))
+			for i, c := range result.Content {
+				content[i] = c
+				text, ok := c.(mcp.TextContent)
+				if !ok || !isJSONDocument(text.Text) {
+					continue
+				}
+				if text.Text, err = path.selectJSON([]byte(text.Text)); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				content[i] = text
+				selected = true
+			}
+			if !selected {
+				return mcp.NewToolResultError(fmt.Sprintf("%s only applies to JSON results, and the result of %s is not JSON", SelectArgument, request.Params.Name)), nil
+			}
+			out := *result
+			out.Content = content
+			return &out, nil
+		}
+	}
+}
+
+// SelectToolFilter adds the select argument to the listed read tools.
+func SelectToolFilter(_ context.Context, tools []mcp.Tool) []mcp.Tool {
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if readOnly := tool.Annotations.ReadOnlyHint; readOnly != nil && *readOnly {
+			tool = WithToolProperty(tool, SelectArgument, map[string]any{
+				"type":        "string",
+				"description": `JSONPath-style path of the parts of the JSON result to return, such as "[*].head.sha" or "items[?(@.state == 'open')].number". Paths with wildcards, slices or filters return a list`,
+			})
+		}
+		filtered = append(filtered, tool)
+	}
+	return filtered
+}
+
+type selectStepKind int
+
+const (
+	selectField selectStepKind = iota
+	selectWildcard
+	selectIndex
+	selectSlice
+	selectFilter
+)
+
+// selectStep is a step of a select path, which selects values from each value selected by the
+// previous step.
+type selectStep struct {
+	kind  selectStepKind
+	field string
+	index int
+	// start and end of a slice, nil when left out
+	start, end *int
+	filter     *selectCondition
+}
+
+// selectCondition keeps the elements of an array whose value at path compares to value with op.
+// Without op, it keeps the elements that have a value other than null or false at path.
+type selectCondition struct {
+	path  []string
+	op    string
+	value any
+}
+
+// selectPath is a parsed select argument.
+type selectPath []selectStep
+
+// selectJSON returns the parts of the JSON data selected by the path, as JSON. Paths with
+// wildcards, slices or filters select a list of values, other paths a single value or null.
+func (p selectPath) selectJSON(data []byte) (string, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return "", err
+	}
+
+	values := []any{v}
+	for _, step := range p {
+		next := make([]any, 0)
+		for _, value := range values {
+			next = step.apply(value, next)
+			if len(next) > maxSelectedValues {
+				return "", fmt.Errorf("%s selects more than %d values", SelectArgument, maxSelectedValues)
+			}
+		}
+		values = next
+	}
+
+	var selected any = values
+	if p.single() {
+		selected = nil
+		if len(values) > 0 {
+			selected = values[0]
+		}
+	}
+	out, err := json.Marshal(selected)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal selected values: %w", err)
+	}
+	return string(out), nil
+}
+
+// single reports whether the path selects a single value, as it only has fields and indexes.
+func (p selectPath) single() bool {
+	for _, step := range p {
+		if step.kind != selectField && step.kind != selectIndex {
+			return false
+		}
+	}
+	return true
+}
+
+// apply appends the values the step selects from v to out.
+func (s selectStep) apply(v any, out []any) []any {
+	switch s.kind {
+	case selectField:
+		if obj, ok := v.(map[string]any); ok {
+			if value, ok := obj[s.field]; ok {
+				out = append(out, value)
+			}
+		}
+	case selectWildcard:
+		switch v := v.(type) {
+		case map[string]any:
+			for _, key := range slices.Sorted(maps.Keys(v)) {
+				out = append(out, v[key])
+			}
+		case []any:
+			out = append(out, v...)
+		}
+	case selectIndex:
+		if list, ok := v.([]any); ok {
+			i := s.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				out = append(out, list[i])
+			}
+		}
+	case selectSlice:
+		if list, ok := v.([]any); ok {
+			start, end := sliceIndex(s.start, 0, len(list)), sliceIndex(s.end, len(list), len(list))
+			if start < end {
+				out = append(out, list[start:end]...)
+			}
+		}
+	case selectFilter:
+		if list, ok := v.([]any); ok {
+			for _, elem := range list {
+				if s.filter.matches(elem) {
+					out = append(out, elem)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// sliceIndex returns the index of a slice bound in a list of length n, counting negative bounds
+// from the end of the list.
+func sliceIndex(bound *int, def, n int) int {
+	if bound == nil {
+		return def
+	}
+	i := *bound
+	if i < 0 {
+		i += n
+	}
+	return min(max(i, 0), n)
+}
+
+func (c *selectCondition) matches(v any) bool {
+	for _, field := range c.path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+		if v, ok = obj[field]; !ok {
+			return false
+		}
+	}
+	if c.op == "" {
+		return v != nil && v != false
+	}
+	return compareValues(v, c.value, c.op)
+}
+
+// compareValues compares numbers with numbers and strings with strings. Other values, and values
+// of different types, are only equal or not.
+func compareValues(a, b any, op string) bool {
+	var order int
+	switch a := a.(type) {
+	case json.Number:
+		n, ok := b.(json.Number)
+		if !ok {
+			return op == "!="
+		}
+		order = compareNumbers(a, n)
+	case string:
+		s, ok := b.(string)
+		if !ok {
+			return op == "!="
+		}
+		order = strings.Compare(a, s)
+	case bool, nil:
+		switch op {
+		case "==":
+			return a == b
+		case "!=":
+			return a != b
+		}
+		return false
+	default:
+		return op == "!="
+	}
+
+	switch op {
+	case "==":
+		return order == 0
+	case "!=":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	default:
+		return order >= 0
+	}
+}
+
+func compareNumbers(a, b json.Number) int {
+	if x, err := a.Int64(); err == nil {
+		if y, err := b.Int64(); err == nil {
+			return cmp.Compare(x, y)
+		}
+	}
+	x, _ := a.Float64()
+	y, _ := b.Float64()
+	return cmp.Compare(x, y)
+}
+
+// parseSelectPath parses a JSONPath-style path: an optional $ followed by .field, ["field"], .*,
+// [*], [index], [start:end] and [?(@.field op value)] steps. The first field may leave out its dot.
+func parseSelectPath(s string) (selectPath, error) {
+	if len(s) > maxSelectLength {
+		return nil, fmt.Errorf("%s is longer than %d characters", SelectArgument, maxSelectLength)
+	}
+	p := &selectParser{s: strings.TrimSpace(s)}
+	path, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", SelectArgument, s, err)
+	}
+	return path, nil
+}
+
+type selectParser struct {
+	s     string
+	pos   int
+	steps int
+}
+
+func (p *selectParser) parse() (selectPath, error) {
+	var path selectPath
+	bare := true
+	if p.peek() == '$' {
+		p.pos++
+		bare = false
+	}
+	for p.pos < len(p.s) {
+		var step selectStep
+		var err error
+		switch {
+		case p.peek() == '.':
+			p.pos++
+			step, err = p.dotStep()
+		case p.peek() == '[':
+			p.pos++
+			step, err = p.bracketStep()
+		case bare:
+			step, err = p.dotStep()
+		default:
+			err = p.errorf("expected . or [")
+		}
+		if err != nil {
+			return nil, err
+		}
+		bare = false
+		path = append(path, step)
+		if err := p.count(1); err != nil {
+			return nil, err
+		}
+	}
+	return path, nil
+}
+
+func (p *selectParser) count(steps int) error {
+	p.steps += steps
+	if p.steps > maxSelectSteps {
+		return fmt.Errorf("more than %d steps", maxSelectSteps)
+	}
+	return nil
+}
+
+// dotStep parses the field or wildcard after a dot.
+func (p *selectParser) dotStep() (selectStep, error) {
+	switch p.peek() {
+	case '*':
+		p.pos++
+		return selectStep{kind: selectWildcard}, nil
+	case '.':
+		return selectStep{}, p.errorf("recursive descent is not supported")
+	}
+	name, err := p.name()
+	if err != nil {
+		return selectStep{}, err
+	}
+	return selectStep{kind: selectField, field: name}, nil
+}
+
+// bracketStep parses the step after an opening bracket, up to the closing bracket.
+func (p *selectParser) bracketStep() (selectStep, error) {
+	p.skipSpaces()
+	var step selectStep
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		step = selectStep{kind: selectWildcard}
+	case c == '"' || c == '\'':
+		field, err := p.quoted()
+		if err != nil {
+			return selectStep{}, err
+		}
+		step = selectStep{kind: selectField, field: field}
+	case c == '?':
+		p.pos++
+		filter, err := p.filter()
+		if err != nil {
+			return selectStep{}, err
+		}
+		step = selectStep{kind: selectFilter, filter: filter}
+	default:
+		end := strings.IndexByte(p.s[p.pos:], ']')
+		if end < 0 {
+			return selectStep{}, p.errorf("missing ]")
+		}
+		var err error
+		if step, err = p.indexOrSlice(strings.TrimSpace(p.s[p.pos : p.pos+end])); err != nil {
+			return selectStep{}, err
+		}
+		p.pos += end
+	}
+	p.skipSpaces()
+	return step, p.expect(']')
+}
+
+func (p *selectParser) indexOrSlice(s string) (selectStep, error) {
+	first, last, isSlice := strings.Cut(s, ":")
+	if !isSlice {
+		index, err := strconv.Atoi(s)
+		if err != nil {
+			return selectStep{}, p.errorf("expected an index, a slice, a quoted field, * or a filter")
+		}
+		return selectStep{kind: selectIndex, index: index}, nil
+	}
+	start, err := p.sliceBound(first)
+	if err != nil {
+		return selectStep{}, err
+	}
+	end, err := p.sliceBound(last)
+	if err != nil {
+		return selectStep{}, err
+	}
+	return selectStep{kind: selectSlice, start: start, end: end}, nil
+}
+
+// sliceBound parses a bound of a slice, nil when it is left out.
+func (p *selectParser) sliceBound(s string) (*int, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, p.errorf("invalid slice bound %q", s)
+	}
+	return &i, nil
+}
+
+// filter parses a filter after its question mark, with or without parentheses.
+func (p *selectParser) filter() (*selectCondition, error) {
+	p.skipSpaces()
+	parens := p.peek() == '('
+	if parens {
+		p.pos++
+		p.skipSpaces()
+	}
+	if err := p.expect('@'); err != nil {
+		return nil, err
+	}
+	condition := &selectCondition{}
+	for p.peek() == '.' {
+		p.pos++
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		condition.path = append(condition.path, name)
+	}
+	if err := p.count(len(condition.path)); err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(p.s[p.pos:], op) {
+			condition.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if condition.op != "" {
+		p.skipSpaces()
+		value, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		condition.value = value
+		p.skipSpaces()
+	}
+	if parens {
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+	}
+	return condition, nil
+}
+
+// literal parses a quoted string, a number, true, false or null.
+func (p *selectParser) literal() (any, error) {
+	if c := p.peek(); c == '"' || c == '\'' {
+		return p.quoted()
+	}
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(" )]", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	decoder := json.NewDecoder(strings.NewReader(p.s[start:p.pos]))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || start == p.pos {
+		return nil, fmt.Errorf("expected a string, a number, true, false or null at %d", start)
+	}
+	switch value.(type) {
+	case map[string]any, []any:
+		return nil, fmt.Errorf("expected a string, a number, true, false or null at %d", start)
+	}
+	return value, nil
+}
+
+// quoted parses a string in double quotes, with JSON escapes, or in single quotes, without escapes.
+func (p *selectParser) quoted() (string, error) {
+	quote := p.s[p.pos]
+	start := p.pos
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		switch p.s[p.pos] {
+		case '\\':
+			if quote == '"' {
+				p.pos++
+			}
+		case quote:
+			p.pos++
+			if quote == '\'' {
+				return p.s[start+1 : p.pos-1], nil
+			}
+			var s string
+			if err := json.Unmarshal([]byte(p.s[start:p.pos]), &s); err != nil {
+				return "", fmt.Errorf("invalid string at %d: %w", start, err)
+			}
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unterminated string at %d", start)
+}
+
+// name parses a field name, which runs up to the next dot, bracket, space or operator.
+func (p *selectParser) name() (string, error) {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(".[] ()=!<>", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.errorf("expected a field name")
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *selectParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *selectParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("expected %c", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *selectParser) skipSpaces() {
+	for p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+func (p *selectParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s at %d", fmt.Sprintf(format, args...), p.pos)
+}
@@ -0,0 +1,220 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SelectJSON(t *testing.T) {
+	prs := `[
+		{"number":1,"state":"open","draft":false,"head":{"sha":"abc","ref":"feature"},"labels":[{"name":"bug"}],"id":1234567890123},
+		{"number":2,"state":"closed","draft":true,"head":{"sha":"def","ref":"fix"},"labels":[],"id":2},
+		{"number":3,"state":"open","head":{"sha":"ghi","ref":"it's"},"labels":[{"name":"docs"}],"id":3}
+	]`
+
+	tests := []struct {
+		name     string
+		data     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "field of every element",
+			data:     prs,
+			path:     "[*].head.sha",
+			expected: `["abc","def","ghi"]`,
+		},
+		{
+			name:     "root and dots",
+			data:     prs,
+			path:     "$.*.number",
+			expected: `[1,2,3]`,
+		},
+		{
+			name:     "single value",
+			data:     prs,
+			path:     "[-1].head.ref",
+			expected: `"it's"`,
+		},
+		{
+			name:     "missing single value",
+			data:     prs,
+			path:     "[5].number",
+			expected: `null`,
+		},
+		{
+			name:     "large numbers are kept",
+			data:     prs,
+			path:     "[0].id",
+			expected: `1234567890123`,
+		},
+		{
+			name:     "slice",
+			data:     prs,
+			path:     "[1:].number",
+			expected: `[2,3]`,
+		},
+		{
+			name:     "filter on a string",
+			data:     prs,
+			path:     `[?(@.state == "open")].number`,
+			expected: `[1,3]`,
+		},
+		{
+			name:     "filter on a nested field without parentheses",
+			data:     prs,
+			path:     `[?@.head.ref != "it's"].number`,
+			expected: `[1,2]`,
+		},
+		{
+			name:     "filter on a string in single quotes",
+			data:     prs,
+			path:     `[?(@.head.ref == 'fix')].number`,
+			expected: `[2]`,
+		},
+		{
+			name:     "filter on a number",
+			data:     prs,
+			path:     `[?(@.number>=2)].head.sha`,
+			expected: `["def","ghi"]`,
+		},
+		{
+			name:     "filter on a value",
+			data:     prs,
+			path:     `[?(@.draft)].number`,
+			expected: `[2]`,
+		},
+		{
+			name:     "filter on a boolean",
+			data:     prs,
+			path:     `[?(@.draft == false)].number`,
+			expected: `[1]`,
+		},
+		{
+			name:     "list in an object",
+			data:     `{"total_count":3,"items":` + prs + `}`,
+			path:     `items[*].labels[*].name`,
+			expected: `["bug","docs"]`,
+		},
+		{
+			name:     "quoted field",
+			data:     `{"results":{"a.b":1}}`,
+			path:     `results["a.b"]`,
+			expected: `1`,
+		},
+		{
+			name:     "filter on the elements",
+			data:     `["main","dev","release"]`,
+			path:     `[?(@ != "dev")]`,
+			expected: `["main","release"]`,
+		},
+		{
+			name:     "empty path",
+			data:     `{"number":1}`,
+			path:     "$",
+			expected: `{"number":1}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := parseSelectPath(tc.path)
+			require.NoError(t, err)
+			selected, err := path.selectJSON([]byte(tc.data))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, selected)
+		})
+	}
+}
+
+func Test_ParseSelectPathErrors(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "items..title", expected: "recursive descent is not supported"},
+		{path: "items[", expected: "missing ]"},
+		{path: "items[x]", expected: "expected an index"},
+		{path: "items[1:x]", expected: `invalid slice bound "x"`},
+		{path: `items["title]`, expected: "unterminated string"},
+		{path: `items[?(@.state == open)]`, expected: "expected a string, a number, true, false or null"},
+		{path: `items[?(@.state == "open"]`, expected: "expected )"},
+		{path: "$items", expected: "expected . or ["},
+		{path: strings.Repeat(".a", maxSelectSteps+1), expected: "more than 32 steps"},
+		{path: strings.Repeat("a", maxSelectLength+1), expected: "longer than 512 characters"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			_, err := parseSelectPath(tc.path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func Test_SelectJSONLimit(t *testing.T) {
+	data := "[" + strings.TrimSuffix(strings.Repeat("[1,2],", maxSelectedValues/2+1), ",") + "]"
+	path, err := parseSelectPath("[*][*]")
+	require.NoError(t, err)
+	_, err = path.selectJSON([]byte(data))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "selects more than 10000 values")
+}
+
+func Test_SelectToolMiddleware(t *testing.T) {
+	called := false
+	handler := SelectToolMiddleware(func(tool string) bool {
+		return tool == "create_issue"
+	})(func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		if request.Params.Name == "get_file_contents" {
+			return mcp.NewToolResultText("package main"), nil
+		}
+		return mcp.NewToolResultText(`[{"number":1,"head":{"sha":"abc"}},{"number":2,"head":{"sha":"def"}}]`), nil
+	})
+
+	call := func(tool string, args map[string]any) *mcp.CallToolResult {
+		request := createMCPRequest(args)
+		request.Params.Name = tool
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call("list_pull_requests", map[string]any{SelectArgument: "[*].head.sha"})
+	require.False(t, result.IsError)
+	assert.Equal(t, `["abc","def"]`, getTextResult(t, result).Text)
+
+	// Without select, and for write tools, the result is returned as is
+	result = call("list_pull_requests", nil)
+	assert.Contains(t, getTextResult(t, result).Text, `"number":1`)
+	result = call("create_issue", map[string]any{SelectArgument: "[*].head.sha"})
+	assert.Contains(t, getTextResult(t, result).Text, `"number":1`)
+
+	// Invalid paths are reported before the tool is called
+	called = false
+	result = call("list_pull_requests", map[string]any{SelectArgument: "[*"})
+	require.True(t, result.IsError)
+	assert.Contains(t, getErrorResult(t, result).Text, `invalid select "[*"`)
+	assert.False(t, called)
+
+	result = call("get_file_contents", map[string]any{SelectArgument: "name"})
+	require.True(t, result.IsError)
+	assert.Contains(t, getErrorResult(t, result).Text, "the result of get_file_contents is not JSON")
+}
+
+func Test_SelectToolFilter(t *testing.T) {
+	read := mcp.NewTool("list_issues", mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: ToBoolPtr(true)}))
+	write := mcp.NewTool("create_issue", mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: ToBoolPtr(false)}))
+	tools := SelectToolFilter(context.Background(), []mcp.Tool{read, write})
+	require.Len(t, tools, 2)
+	assert.Contains(t, tools[0].InputSchema.Properties, SelectArgument)
+	assert.NotContains(t, tools[1].InputSchema.Properties, SelectArgument)
+	assert.NotContains(t, read.InputSchema.Properties, SelectArgument, "the registered tool is not changed")
+}
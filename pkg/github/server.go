@@ -1,9 +1,11 @@
 package github
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 
 	"github.com/google/go-github/v73/github"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -188,6 +190,8 @@ func WithPagination() mcp.ToolOption {
 			mcp.Min(1),
 			mcp.Max(100),
 		)(tool)
+
+		withFetchAll()(tool)
 	}
 }
 
@@ -209,6 +213,8 @@ func WithUnifiedPagination() mcp.ToolOption {
 		mcp.WithString("after",
 			mcp.Description("Cursor for pagination. Use the endCursor from the previous page's PageInfo for GraphQL APIs."),
 		)(tool)
+
+		withFetchAll()(tool)
 	}
 }
 
@@ -224,17 +230,36 @@ func WithCursorPagination() mcp.ToolOption {
 		mcp.WithString("after",
 			mcp.Description("Cursor for pagination. Use the endCursor from the previous page's PageInfo for GraphQL APIs."),
 		)(tool)
+
+		withFetchAll()(tool)
+	}
+}
+
+// withFetchAll adds the parameters to fetch the following pages in the same call to a tool.
+func withFetchAll() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithBoolean("fetch_all",
+			mcp.Description("Fetch the following pages too and return the results merged, up to max_results, starting with the first result of page"),
+		)(tool)
+
+		mcp.WithNumber("max_results",
+			mcp.Description(fmt.Sprintf("Maximum number of results to return with fetch_all (min 1, max %d, default %d)", maxFetchAllResults, maxFetchAllResults)),
+			mcp.Min(1),
+			mcp.Max(maxFetchAllResults),
+		)(tool)
 	}
 }
 
 type PaginationParams struct {
-	Page    int
-	PerPage int
-	After   string
+	Page       int
+	PerPage    int
+	After      string
+	FetchAll   bool
+	MaxResults int
 }
 
-// OptionalPaginationParams returns the "page", "perPage", and "after" parameters from the request,
-// or their default values if not present, "page" default is 1, "perPage" default is 30.
+// OptionalPaginationParams returns the "page", "perPage", "after", "fetch_all" and "max_results" parameters
+// from the request, or their default values if not present, "page" default is 1, "perPage" default is 30.
 // In future, we may want to make the default values configurable, or even have this
 // function returned from `withPagination`, where the defaults are provided alongside
 // the min/max values.
@@ -251,15 +276,38 @@ func OptionalPaginationParams(r mcp.CallToolRequest) (PaginationParams, error) {
 	if err != nil {
 		return PaginationParams{}, err
 	}
+	fetchAll, maxResults, err := optionalFetchAllParams(r)
+	if err != nil {
+		return PaginationParams{}, err
+	}
 	return PaginationParams{
-		Page:    page,
-		PerPage: perPage,
-		After:   after,
+		Page:       page,
+		PerPage:    perPage,
+		After:      after,
+		FetchAll:   fetchAll,
+		MaxResults: maxResults,
 	}, nil
 }
 
-// OptionalCursorPaginationParams returns the "perPage" and "after" parameters from the request,
-// without the "page" parameter, suitable for cursor-based pagination only.
+// optionalFetchAllParams returns the "fetch_all" and "max_results" parameters from the request.
+// A max_results of zero stands for the default.
+func optionalFetchAllParams(r mcp.CallToolRequest) (bool, int, error) {
+	fetchAll, err := OptionalParam[bool](r, "fetch_all")
+	if err != nil {
+		return false, 0, err
+	}
+	maxResults, err := OptionalIntParam(r, "max_results")
+	if err != nil {
+		return false, 0, err
+	}
+	if maxResults < 0 {
+		return false, 0, fmt.Errorf("max_results value %d cannot be negative", maxResults)
+	}
+	return fetchAll, maxResults, nil
+}
+
+// OptionalCursorPaginationParams returns the "perPage", "after", "fetch_all" and "max_results" parameters
+// from the request, without the "page" parameter, suitable for cursor-based pagination only.
 func OptionalCursorPaginationParams(r mcp.CallToolRequest) (CursorPaginationParams, error) {
 	perPage, err := OptionalIntParamWithDefault(r, "perPage", 30)
 	if err != nil {
@@ -269,15 +317,23 @@ func OptionalCursorPaginationParams(r mcp.CallToolRequest) (CursorPaginationPara
 	if err != nil {
 		return CursorPaginationParams{}, err
 	}
+	fetchAll, maxResults, err := optionalFetchAllParams(r)
+	if err != nil {
+		return CursorPaginationParams{}, err
+	}
 	return CursorPaginationParams{
-		PerPage: perPage,
-		After:   after,
+		PerPage:    perPage,
+		After:      after,
+		FetchAll:   fetchAll,
+		MaxResults: maxResults,
 	}, nil
 }
 
 type CursorPaginationParams struct {
-	PerPage int
-	After   string
+	PerPage    int
+	After      string
+	FetchAll   bool
+	MaxResults int
 }
 
 // ToGraphQLParams converts cursor pagination parameters to GraphQL-specific parameters.
@@ -318,6 +374,31 @@ func (p PaginationParams) ToGraphQLParams() (*GraphQLPaginationParams, error) {
 	return cursor.ToGraphQLParams()
 }
 
+// WithToolProperty returns tool with the property name added to its input schema, for tool filters
+// that offer an argument handled by a middleware. The properties are shared with the registered
+// tool, so they are copied before being changed.
+func WithToolProperty(tool mcp.Tool, name string, schema map[string]any) mcp.Tool {
+	properties := maps.Clone(tool.InputSchema.Properties)
+	if properties == nil {
+		properties = make(map[string]any)
+	}
+	properties[name] = schema
+	tool.InputSchema.Properties = properties
+	return tool
+}
+
+// decodeJSON decodes JSON data into generic values, keeping numbers as json.Number, as IDs do not
+// survive the trip through float64.
+func decodeJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
 func MarshalledTextResult(v any) *mcp.CallToolResult {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/github/github-mcp-server/pkg/raw"
 	"github.com/google/go-github/v73/github"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/shurcooL/githubv4"
 	"github.com/stretchr/testify/assert"
 )
@@ -44,6 +45,12 @@ func stubGetRawClientFn(client *raw.Client) raw.GetRawClientFn {
 	}
 }
 
+func stubGetHTTPClientFn(client *http.Client) GetHTTPClientFn {
+	return func(_ context.Context) (*http.Client, error) {
+		return client, nil
+	}
+}
+
 func badRequestHandler(msg string) http.HandlerFunc {
 	return func(w http.ResponseWriter, _ *http.Request) {
 		structuredErrorResponse := github.ErrorResponse{
@@ -528,6 +535,29 @@ func TestOptionalPaginationParams(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "fetch_all and max_results parameters",
+			params: map[string]any{
+				"fetch_all":   true,
+				"max_results": float64(250),
+			},
+			expected: PaginationParams{
+				Page:       1,
+				PerPage:    30,
+				FetchAll:   true,
+				MaxResults: 250,
+			},
+			expectError: false,
+		},
+		{
+			name: "negative max_results parameter",
+			params: map[string]any{
+				"fetch_all":   true,
+				"max_results": float64(-1),
+			},
+			expected:    PaginationParams{},
+			expectError: true,
+		},
 		{
 			name: "invalid page parameter",
 			params: map[string]any{
@@ -560,3 +590,14 @@ func TestOptionalPaginationParams(t *testing.T) {
 		})
 	}
 }
+
+func Test_WithToolProperty(t *testing.T) {
+	registered := mcp.NewTool("list_issues", mcp.WithString("owner"))
+	tool := WithToolProperty(registered, "select", map[string]any{"type": "string"})
+	assert.Equal(t, map[string]any{"type": "string"}, tool.InputSchema.Properties["select"])
+	assert.Contains(t, tool.InputSchema.Properties, "owner")
+	assert.NotContains(t, registered.InputSchema.Properties, "select", "the registered tool is not changed")
+
+	tool = WithToolProperty(mcp.Tool{Name: "get_me"}, "select", map[string]any{"type": "string"})
+	assert.Contains(t, tool.InputSchema.Properties, "select")
+}
@@ -2,6 +2,7 @@ package github
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/github/github-mcp-server/pkg/raw"
 	"github.com/github/github-mcp-server/pkg/toolsets"
@@ -14,9 +15,13 @@ import (
 type GetClientFn func(context.Context) (*github.Client, error)
 type GetGQLClientFn func(context.Context) (*githubv4.Client, error)
 
+// GetHTTPClientFn returns the client for downloads from URLs handed out by the API, such as job
+// logs, which must not send GitHub credentials.
+type GetHTTPClientFn func(context.Context) (*http.Client, error)
+
 var DefaultTools = []string{"all"}
 
-func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetGQLClientFn, getRawClient raw.GetRawClientFn, t translations.TranslationHelperFunc) *toolsets.ToolsetGroup {
+func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetGQLClientFn, getRawClient raw.GetRawClientFn, getHTTPClient GetHTTPClientFn, t translations.TranslationHelperFunc) *toolsets.ToolsetGroup {
 	tsg := toolsets.NewToolsetGroup(readOnly)
 
 	// Define all available features with their default state (disabled)
@@ -53,17 +58,18 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 			toolsets.NewServerTool(SearchIssues(getClient, t)),
 			toolsets.NewServerTool(ListIssues(getClient, t)),
 			toolsets.NewServerTool(GetIssueComments(getClient, t)),
-			toolsets.NewServerTool(ListSubIssues(getClient, t)),
+			toolsets.NewServerTool(ListSubIssues(getClient, t)).RequireGHESVersion("3.17"),
 		).
 		AddWriteTools(
 			toolsets.NewServerTool(CreateIssue(getClient, t)),
 			toolsets.NewServerTool(AddIssueComment(getClient, t)),
 			toolsets.NewServerTool(UpdateIssue(getClient, t)),
-			toolsets.NewServerTool(AssignCopilotToIssue(getGQLClient, t)),
-			toolsets.NewServerTool(AddSubIssue(getClient, t)),
-			toolsets.NewServerTool(RemoveSubIssue(getClient, t)),
-			toolsets.NewServerTool(ReprioritizeSubIssue(getClient, t)),
-		).AddPrompts(toolsets.NewServerPrompt(AssignCodingAgentPrompt(t)))
+			toolsets.NewServerTool(AssignCopilotToIssue(getGQLClient, t)).UnavailableOnGHES(),
+			toolsets.NewServerTool(AddSubIssue(getClient, t)).RequireGHESVersion("3.17"),
+			toolsets.NewServerTool(RemoveSubIssue(getClient, t)).RequireGHESVersion("3.17"),
+			toolsets.NewServerTool(ReprioritizeSubIssue(getClient, t)).RequireGHESVersion("3.17"),
+		).
+		AddPrompts(toolsets.NewServerPrompt(AssignCodingAgentPrompt(t)))
 	users := toolsets.NewToolset("users", "GitHub User related tools").
 		AddReadTools(
 			toolsets.NewServerTool(SearchUsers(getClient, t)),
@@ -88,7 +94,7 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 			toolsets.NewServerTool(UpdatePullRequestBranch(getClient, t)),
 			toolsets.NewServerTool(CreatePullRequest(getClient, t)),
 			toolsets.NewServerTool(UpdatePullRequest(getClient, t)),
-			toolsets.NewServerTool(RequestCopilotReview(getClient, t)),
+			toolsets.NewServerTool(RequestCopilotReview(getClient, t)).UnavailableOnGHES(),
 
 			// Reviews
 			toolsets.NewServerTool(CreateAndSubmitPullRequestReview(getGQLClient, t)),
@@ -127,10 +133,12 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 
 	discussions := toolsets.NewToolset("discussions", "GitHub Discussions related tools").
 		AddReadTools(
-			toolsets.NewServerTool(ListDiscussions(getGQLClient, t)),
-			toolsets.NewServerTool(GetDiscussion(getGQLClient, t)),
-			toolsets.NewServerTool(GetDiscussionComments(getGQLClient, t)),
-			toolsets.NewServerTool(ListDiscussionCategories(getGQLClient, t)),
+			toolsets.NewServerTool(ListDiscussions(getGQLClient, t)).
+				RequireGHESVersion("3.6").
+				RequireGHESVersionForArguments("3.13", "orderBy", "direction"),
+			toolsets.NewServerTool(GetDiscussion(getGQLClient, t)).RequireGHESVersion("3.6"),
+			toolsets.NewServerTool(GetDiscussionComments(getGQLClient, t)).RequireGHESVersion("3.6"),
+			toolsets.NewServerTool(ListDiscussionCategories(getGQLClient, t)).RequireGHESVersion("3.6"),
 		)
 
 	actions := toolsets.NewToolset("actions", "GitHub Actions workflows and CI/CD operations").
@@ -140,7 +148,7 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 			toolsets.NewServerTool(GetWorkflowRun(getClient, t)),
 			toolsets.NewServerTool(GetWorkflowRunLogs(getClient, t)),
 			toolsets.NewServerTool(ListWorkflowJobs(getClient, t)),
-			toolsets.NewServerTool(GetJobLogs(getClient, t)),
+			toolsets.NewServerTool(GetJobLogs(getClient, getHTTPClient, t)),
 			toolsets.NewServerTool(ListWorkflowRunArtifacts(getClient, t)),
 			toolsets.NewServerTool(DownloadWorkflowRunArtifact(getClient, t)),
 			toolsets.NewServerTool(GetWorkflowRunUsage(getClient, t)),
@@ -179,15 +187,16 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 	return tsg
 }
 
-// InitDynamicToolset creates a dynamic toolset that can be used to enable other toolsets, and so requires the server and toolset group as arguments
-func InitDynamicToolset(s *server.MCPServer, tsg *toolsets.ToolsetGroup, t translations.TranslationHelperFunc) *toolsets.Toolset {
+// InitDynamicToolset creates a dynamic toolset that can be used to enable and disable other toolsets, and so requires the server and toolset group as arguments
+func InitDynamicToolset(s *server.MCPServer, tsg *toolsets.ToolsetGroup, sessions *SessionTools, t translations.TranslationHelperFunc) *toolsets.Toolset {
 	// Create a new dynamic toolset
 	// Need to add the dynamic toolset last so it can be used to enable other toolsets
 	dynamicToolSelection := toolsets.NewToolset("dynamic", "Discover GitHub MCP tools that can help achieve tasks by enabling additional sets of tools, you can control the enablement of any toolset to access its tools when this toolset is enabled.").
 		AddReadTools(
 			toolsets.NewServerTool(ListAvailableToolsets(tsg, t)),
 			toolsets.NewServerTool(GetToolsetsTools(tsg, t)),
-			toolsets.NewServerTool(EnableToolset(s, tsg, t)),
+			toolsets.NewServerTool(EnableToolset(s, tsg, sessions, t)),
+			toolsets.NewServerTool(DisableToolset(s, tsg, sessions, t)),
 		)
 
 	dynamicToolSelection.Enabled = true
@@ -0,0 +1,167 @@
+// Package httpcache provides an HTTP transport that revalidates cached GitHub API responses with
+// conditional requests, which GitHub does not count against the rate limit when they return 304.
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+// XFromCache is set on responses that were served from the cache after GitHub confirmed that
+// they are still fresh.
+const XFromCache = "X-From-Cache"
+
+// Store persists serialized responses under opaque keys.
+type Store interface {
+	// Get returns the value stored under key, if any
+	Get(key string) ([]byte, bool)
+
+	// Set stores value under key, replacing any previous value
+	Set(key string, value []byte)
+
+	// Delete removes the value stored under key
+	Delete(key string)
+}
+
+// Transport caches successful GET responses that carry an ETag or Last-Modified header. Cached
+// responses are never served without revalidation: every request is sent to GitHub with
+// If-None-Match or If-Modified-Since, and the cached body is only used once GitHub answers
+// 304 Not Modified, so responses are never stale.
+//
+// Cache entries are partitioned by the Authorization header of the request, so that a response
+// fetched with one token is never served for another.
+type Transport struct {
+	transport http.RoundTripper
+	store     Store
+}
+
+// NewTransport wraps transport with a conditional request cache backed by store.
+func NewTransport(transport http.RoundTripper, store Store) *Transport {
+	return &Transport{
+		transport: transport,
+		store:     store,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !cacheable(req) {
+		return t.transport.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	cached := t.load(key, req)
+
+	outReq := req
+	if cached != nil {
+		outReq = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			outReq.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			outReq.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		// The 304 carries up to date headers, such as the current rate limit budget
+		for name, values := range resp.Header {
+			cached.Header[name] = values
+		}
+		t.save(key, cached)
+		cached.Header.Set(XFromCache, "1")
+		return cached, nil
+	}
+	if cached != nil {
+		_ = cached.Body.Close()
+		if resp.StatusCode < http.StatusInternalServerError {
+			// Whatever was cached is outdated now
+			t.store.Delete(key)
+		}
+	}
+
+	if !storable(resp) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	t.save(key, resp)
+	return resp, nil
+}
+
+// load returns the response cached under key, or nil if there is none.
+func (t *Transport) load(key string, req *http.Request) *http.Response {
+	value, ok := t.store.Get(key)
+	if !ok {
+		return nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(value)), req)
+	if err != nil {
+		t.store.Delete(key)
+		return nil
+	}
+	return resp
+}
+
+// save serializes resp into the store, leaving its body to be read by the caller.
+func (t *Transport) save(key string, resp *http.Response) {
+	value, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return
+	}
+	t.store.Set(key, value)
+}
+
+// cacheable reports whether the response to req may be served from the cache.
+func cacheable(req *http.Request) bool {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return false
+	}
+	return !strings.Contains(req.Header.Get("Cache-Control"), "no-store")
+}
+
+// storable reports whether resp can be cached and revalidated later.
+func storable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	if strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return false
+	}
+	return resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// cacheKey identifies the response to req. It includes the Authorization header to isolate the
+// responses of different tokens, and is hashed so that the token can't be recovered from the store.
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{
+		req.Header.Get("Authorization"),
+		req.Header.Get("Accept"),
+		req.Header.Get("X-GitHub-Api-Version"),
+		req.URL.String(),
+	} {
+		_, _ = io.WriteString(h, part)
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
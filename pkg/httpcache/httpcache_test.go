@@ -0,0 +1,176 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// etagServer serves a body per token with an ETag, answering 304 when the ETag still matches.
+func etagServer(t *testing.T, bodies map[string]string) (*httptest.Server, *[]*http.Request) {
+	t.Helper()
+
+	var requests []*http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		body := bodies[r.Header.Get("Authorization")]
+		etag := `"` + body + `"`
+
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		if r.Header.Get("If-None-Match") == etag {
+			w.Header().Set("X-RateLimit-Remaining", "4998")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &requests
+}
+
+func get(t *testing.T, client *http.Client, url, token string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func Test_Transport_Revalidates(t *testing.T) {
+	bodies := map[string]string{"Bearer alice": `{"login":"alice"}`}
+	ts, requests := etagServer(t, bodies)
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport, NewMemoryStore(1<<20))}
+
+	resp, body := get(t, client, ts.URL+"/user", "alice")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"login":"alice"}`, body)
+	assert.Empty(t, resp.Header.Get(XFromCache))
+
+	resp, body = get(t, client, ts.URL+"/user", "alice")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"login":"alice"}`, body)
+	assert.Equal(t, "1", resp.Header.Get(XFromCache))
+	assert.Equal(t, "4998", resp.Header.Get("X-RateLimit-Remaining"), "headers should be updated from the 304")
+
+	require.Len(t, *requests, 2)
+	assert.Empty(t, (*requests)[0].Header.Get("If-None-Match"))
+	assert.Equal(t, `"{"login":"alice"}"`, (*requests)[1].Header.Get("If-None-Match"))
+
+	// Once the resource changes, the new response replaces the cached one
+	bodies["Bearer alice"] = `{"login":"alice","name":"Alice"}`
+	resp, body = get(t, client, ts.URL+"/user", "alice")
+	assert.Empty(t, resp.Header.Get(XFromCache))
+	assert.Equal(t, `{"login":"alice","name":"Alice"}`, body)
+
+	resp, body = get(t, client, ts.URL+"/user", "alice")
+	assert.Equal(t, "1", resp.Header.Get(XFromCache))
+	assert.Equal(t, `{"login":"alice","name":"Alice"}`, body)
+}
+
+func Test_Transport_IsolatesTokens(t *testing.T) {
+	ts, requests := etagServer(t, map[string]string{
+		"Bearer alice": `{"login":"alice"}`,
+		"Bearer bob":   `{"login":"bob"}`,
+	})
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport, NewMemoryStore(1<<20))}
+
+	_, body := get(t, client, ts.URL+"/user", "alice")
+	assert.Equal(t, `{"login":"alice"}`, body)
+
+	resp, body := get(t, client, ts.URL+"/user", "bob")
+	assert.Equal(t, `{"login":"bob"}`, body)
+	assert.Empty(t, resp.Header.Get(XFromCache))
+
+	require.Len(t, *requests, 2)
+	assert.Empty(t, (*requests)[1].Header.Get("If-None-Match"), "another token's ETag should never be sent")
+}
+
+func Test_Transport_SkipsUncacheableRequests(t *testing.T) {
+	ts, requests := etagServer(t, map[string]string{"Bearer alice": `{"login":"alice"}`})
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport, NewMemoryStore(1<<20))}
+
+	get(t, client, ts.URL+"/user", "alice")
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/user", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer alice")
+	req.Header.Set("Cache-Control", "no-store")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	req, err = http.NewRequest(http.MethodPost, ts.URL+"/user", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer alice")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Len(t, *requests, 3)
+	assert.Empty(t, (*requests)[1].Header.Get("If-None-Match"))
+	assert.Empty(t, (*requests)[2].Header.Get("If-None-Match"))
+}
+
+func Test_MemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemoryStore(10)
+
+	store.Set("a", []byte("aaaa"))
+	store.Set("b", []byte("bbbb"))
+	_, ok := store.Get("a")
+	require.True(t, ok)
+
+	// "b" is now the least recently used entry
+	store.Set("c", []byte("cccc"))
+	_, ok = store.Get("b")
+	assert.False(t, ok)
+	_, ok = store.Get("a")
+	assert.True(t, ok)
+	_, ok = store.Get("c")
+	assert.True(t, ok)
+
+	store.Set("d", []byte("more than ten bytes"))
+	_, ok = store.Get("d")
+	assert.False(t, ok, "entries larger than the store should not be kept")
+
+	store.Delete("a")
+	_, ok = store.Get("a")
+	assert.False(t, ok)
+}
+
+func Test_DiskStore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewDiskStore(dir, 10)
+	require.NoError(t, err)
+	store.Set("a", []byte("aaaa"))
+	store.Set("b", []byte("bbbb"))
+
+	// A new store picks up the entries of the previous one
+	store, err = NewDiskStore(dir, 10)
+	require.NoError(t, err)
+	value, ok := store.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "aaaa", string(value))
+
+	store.Set("c", []byte("cccc"))
+	_, ok = store.Get("b")
+	assert.False(t, ok)
+	assert.NoFileExists(t, dir+"/b", "evicted entries should be removed from disk")
+
+	store.Delete("c")
+	assert.NoFileExists(t, dir+"/c")
+}
@@ -0,0 +1,208 @@
+package httpcache
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// lru tracks the size and recency of cache entries, evicting the least recently used entries
+// once their total size exceeds maxBytes.
+type lru struct {
+	maxBytes int64
+	size     int64
+	order    *list.List
+	entries  map[string]*list.Element
+	onEvict  func(key string)
+}
+
+type lruEntry struct {
+	key   string
+	size  int64
+	value []byte
+}
+
+func newLRU(maxBytes int64, onEvict func(key string)) *lru {
+	return &lru{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		onEvict:  onEvict,
+	}
+}
+
+func (c *lru) get(key string) (*lruEntry, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry), true
+}
+
+// add inserts or replaces an entry, reporting false if the entry is too large to ever fit.
+func (c *lru) add(entry *lruEntry) bool {
+	c.remove(entry.key)
+	if entry.size > c.maxBytes {
+		return false
+	}
+
+	c.entries[entry.key] = c.order.PushFront(entry)
+	c.size += entry.size
+	for c.size > c.maxBytes {
+		oldest := c.order.Back().Value.(*lruEntry)
+		c.remove(oldest.key)
+		if c.onEvict != nil {
+			c.onEvict(oldest.key)
+		}
+	}
+	return true
+}
+
+func (c *lru) remove(key string) {
+	element, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.order.Remove(element)
+	delete(c.entries, key)
+	c.size -= element.Value.(*lruEntry).size
+}
+
+// MemoryStore is a Store that keeps responses in memory, bounded in total size.
+type MemoryStore struct {
+	mu  sync.Mutex
+	lru *lru
+}
+
+// NewMemoryStore creates a store holding at most maxBytes of responses, evicting the least
+// recently used ones first.
+func NewMemoryStore(maxBytes int64) *MemoryStore {
+	return &MemoryStore{lru: newLRU(maxBytes, nil)}
+}
+
+func (s *MemoryStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lru.get(key)
+	if !ok {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (s *MemoryStore) Set(key string, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lru.add(&lruEntry{key: key, size: int64(len(value)), value: value})
+}
+
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lru.remove(key)
+}
+
+// DiskStore is a Store that keeps responses as files in a directory, so that they survive
+// restarts, bounded in total size. Keys must be valid file names, like those used by Transport.
+type DiskStore struct {
+	dir string
+
+	mu  sync.Mutex
+	lru *lru
+}
+
+// NewDiskStore opens a store in dir holding at most maxBytes of responses, creating the directory
+// if necessary. Entries left by a previous run are kept, the least recently modified being evicted first.
+func NewDiskStore(dir string, maxBytes int64) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	s := &DiskStore{dir: dir}
+	s.lru = newLRU(maxBytes, func(key string) {
+		_ = os.Remove(s.path(key))
+	})
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	var infos []os.FileInfo
+	for _, dirEntry := range dirEntries {
+		if dirEntry.Type().IsRegular() && filepath.Ext(dirEntry.Name()) == "" {
+			if info, err := dirEntry.Info(); err == nil {
+				infos = append(infos, info)
+			}
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+	for _, info := range infos {
+		if !s.lru.add(&lruEntry{key: info.Name(), size: info.Size()}) {
+			_ = os.Remove(s.path(info.Name()))
+		}
+	}
+
+	return s, nil
+}
+
+func (s *DiskStore) path(key string) string {
+	return filepath.Join(s.dir, key)
+}
+
+func (s *DiskStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lru.get(key); !ok {
+		return nil, false
+	}
+	value, err := os.ReadFile(s.path(key))
+	if err != nil {
+		s.lru.remove(key)
+		return nil, false
+	}
+	return value, true
+}
+
+func (s *DiskStore) Set(key string, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Write to a temporary file first so that readers never see a partial entry
+	tmp, err := os.CreateTemp(s.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(value)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+
+	if !s.lru.add(&lruEntry{key: key, size: int64(len(value))}) {
+		_ = os.Remove(s.path(key))
+	}
+}
+
+func (s *DiskStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lru.remove(key)
+	_ = os.Remove(s.path(key))
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"strings"
+)
+
+// namedSegments are REST path segments that are followed by a single user supplied name.
+var namedSegments = map[string]string{
+	"users":   "{username}",
+	"orgs":    "{org}",
+	"gists":   "{gist_id}",
+	"teams":   "{team_slug}",
+	"commits": "{ref}",
+	"labels":  "{name}",
+	"trees":   "{tree_sha}",
+	"blobs":   "{file_sha}",
+}
+
+// trailingSegments are REST path segments that are followed by a user supplied value that may
+// itself contain slashes, such as a file path or a branch name.
+var trailingSegments = map[string]string{
+	"contents": "{path}",
+	"readme":   "{dir}",
+	"branches": "{branch}",
+	"ref":      "{ref}",
+	"refs":     "{ref}",
+	"compare":  "{basehead}",
+	"tags":     "{tag}",
+	"tarball":  "{ref}",
+	"zipball":  "{ref}",
+}
+
+// RESTEndpoint turns the path of a REST API request, relative to the API base URL, into a
+// template like "/repos/{owner}/{repo}/issues/{id}" that doesn't contain user supplied values.
+func RESTEndpoint(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) == 1 && segments[0] == "" {
+		return "/"
+	}
+
+	template := make([]string, 0, len(segments))
+	// previous is the previous segment if it was kept as is, rather than replaced by a placeholder
+	previous := ""
+	for i := 0; i < len(segments); i++ {
+		segment := segments[i]
+		switch {
+		case i == 0 && segment == "repos" && len(segments) >= 3:
+			template = append(template, segment, "{owner}", "{repo}")
+			i += 2
+			previous = ""
+			continue
+		case trailingSegments[previous] != "":
+			template = append(template, trailingSegments[previous])
+			return "/" + strings.Join(template, "/")
+		case namedSegments[previous] != "":
+			template = append(template, namedSegments[previous])
+			previous = ""
+			continue
+		case isID(segment):
+			template = append(template, "{id}")
+			previous = ""
+			continue
+		}
+		template = append(template, segment)
+		previous = segment
+	}
+	return "/" + strings.Join(template, "/")
+}
+
+// isID reports whether a path segment is a numeric ID or a commit SHA.
+func isID(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	digits, hex := true, len(segment) == 40
+	for _, r := range segment {
+		isDigit := r >= '0' && r <= '9'
+		digits = digits && isDigit
+		hex = hex && (isDigit || (r >= 'a' && r <= 'f'))
+	}
+	return digits || hex
+}
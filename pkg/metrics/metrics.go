@@ -0,0 +1,171 @@
+// Package metrics collects Prometheus metrics about MCP tool calls and the GitHub API requests they make.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/ratelimit"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "github_mcp"
+
+// Metrics holds the collectors of a server, registered on a registry of their own.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	toolCalls    *prometheus.CounterVec
+	toolDuration *prometheus.HistogramVec
+
+	apiRequests        *prometheus.CounterVec
+	apiDuration        *prometheus.HistogramVec
+	rateLimitRemaining *prometheus.GaugeVec
+	rateLimitLimit     *prometheus.GaugeVec
+
+	now func() time.Time
+}
+
+// New creates the metrics of a server, including the standard Go runtime and process metrics.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tool_calls_total",
+			Help:      "Number of tool calls, by tool, toolset and whether the call succeeded.",
+		}, []string{"tool", "toolset", "result"}),
+		toolDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "tool_call_duration_seconds",
+			Help:      "Duration of tool calls, by tool and toolset.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"tool", "toolset"}),
+		apiRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "github_requests_total",
+			Help:      "Number of requests sent to GitHub, by API, method, endpoint and response status code.",
+		}, []string{"api", "method", "endpoint", "status"}),
+		apiDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "github_request_duration_seconds",
+			Help:      "Duration of requests sent to GitHub, by API, method and endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"api", "method", "endpoint"}),
+		rateLimitRemaining: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "github_rate_limit_remaining",
+			Help:      "Remaining GitHub rate limit budget reported by the latest response, by rate limit resource.",
+		}, []string{"resource"}),
+		rateLimitLimit: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "github_rate_limit_limit",
+			Help:      "GitHub rate limit budget per window reported by the latest response, by rate limit resource.",
+		}, []string{"resource"}),
+		now: time.Now,
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.toolCalls,
+		m.toolDuration,
+		m.apiRequests,
+		m.apiDuration,
+		m.rateLimitRemaining,
+		m.rateLimitLimit,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// unknownTool is the tool label of calls of tools the server does not have.
+const unknownTool = "unknown"
+
+// ToolMiddleware records the tool calls it wraps. toolsetOf returns the toolset a tool belongs to,
+// or an empty string for tools the server does not have. Tool names are sent by the client, so
+// calls of such tools are counted together as unknownTool to keep the number of label values
+// bounded.
+func (m *Metrics) ToolMiddleware(toolsetOf func(tool string) string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			started := m.now()
+			result, err := next(ctx, request)
+
+			tool := request.Params.Name
+			toolset := toolsetOf(tool)
+			if toolset == "" {
+				tool = unknownTool
+			}
+			m.countToolCall(tool, toolset, err == nil && (result == nil || !result.IsError))
+			m.toolDuration.WithLabelValues(tool, toolset).Observe(m.now().Sub(started).Seconds())
+			return result, err
+		}
+	}
+}
+
+// AddHooks counts the calls of tools the server the hooks are installed on does not have, which
+// are rejected before any tool middleware runs.
+func (m *Metrics) AddHooks(hooks *server.Hooks) {
+	hooks.AddOnError(func(_ context.Context, _ any, method mcp.MCPMethod, _ any, err error) {
+		if method == mcp.MethodToolsCall && errors.Is(err, server.ErrToolNotFound) {
+			m.countToolCall(unknownTool, "", false)
+		}
+	})
+}
+
+func (m *Metrics) countToolCall(tool, toolset string, success bool) {
+	result := "success"
+	if !success {
+		result = "error"
+	}
+	m.toolCalls.WithLabelValues(tool, toolset, result).Inc()
+}
+
+// Transport records every request sent through transport. classify returns the GitHub API a
+// request targets and its endpoint, which should be a template such as "/repos/{owner}/{repo}"
+// to keep the number of label values bounded.
+func (m *Metrics) Transport(transport http.RoundTripper, classify func(req *http.Request) (api, endpoint string)) http.RoundTripper {
+	return &metricsTransport{
+		transport: transport,
+		metrics:   m,
+		classify:  classify,
+	}
+}
+
+type metricsTransport struct {
+	transport http.RoundTripper
+	metrics   *Metrics
+	classify  func(req *http.Request) (api, endpoint string)
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	api, endpoint := t.classify(req)
+	start := t.metrics.now()
+
+	resp, err := t.transport.RoundTrip(req)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+		if rate, ok := ratelimit.ParseRate(resp.Header); ok && rate.Resource != "" {
+			t.metrics.rateLimitRemaining.WithLabelValues(rate.Resource).Set(float64(rate.Remaining))
+			t.metrics.rateLimitLimit.WithLabelValues(rate.Resource).Set(float64(rate.Limit))
+		}
+	}
+
+	t.metrics.apiRequests.WithLabelValues(api, req.Method, endpoint, status).Inc()
+	t.metrics.apiDuration.WithLabelValues(api, req.Method, endpoint).Observe(t.metrics.now().Sub(start).Seconds())
+	return resp, err
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RESTEndpoint(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "", expected: "/"},
+		{path: "user", expected: "/user"},
+		{path: "/users/octocat/repos", expected: "/users/{username}/repos"},
+		{path: "repos/octo/hello-world", expected: "/repos/{owner}/{repo}"},
+		{path: "repos/octo/hello-world/issues/42/comments", expected: "/repos/{owner}/{repo}/issues/{id}/comments"},
+		{path: "repos/octo/hello-world/contents/docs/README.md", expected: "/repos/{owner}/{repo}/contents/{path}"},
+		{path: "repos/octo/hello-world/branches/feature/login", expected: "/repos/{owner}/{repo}/branches/{branch}"},
+		{path: "repos/octo/hello-world/git/ref/heads/main", expected: "/repos/{owner}/{repo}/git/ref/{ref}"},
+		{path: "repos/octo/hello-world/commits/main/status", expected: "/repos/{owner}/{repo}/commits/{ref}/status"},
+		{path: "repos/octo/hello-world/git/trees/6dcb09b5b57875f334f61aebed695e2e4193db5e", expected: "/repos/{owner}/{repo}/git/trees/{tree_sha}"},
+		{path: "repos/octo/hello-world/releases/tags/v1.0.0", expected: "/repos/{owner}/{repo}/releases/tags/{tag}"},
+		{path: "orgs/github/teams/justice-league/members", expected: "/orgs/{org}/teams/{team_slug}/members"},
+		{path: "notifications/threads/123", expected: "/notifications/threads/{id}"},
+		{path: "search/issues", expected: "/search/issues"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RESTEndpoint(tc.path))
+		})
+	}
+}
+
+func Test_Transport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		w.Header().Set("X-RateLimit-Resource", "core")
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	m := New()
+	client := &http.Client{Transport: m.Transport(http.DefaultTransport, func(req *http.Request) (string, string) {
+		return "rest", RESTEndpoint(req.URL.Path)
+	})}
+
+	for _, path := range []string{"/repos/octo/a", "/repos/octo/b", "/repos/octo/a/missing"} {
+		resp, err := client.Get(ts.URL + path)
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.apiRequests.WithLabelValues("rest", "GET", "/repos/{owner}/{repo}", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.apiRequests.WithLabelValues("rest", "GET", "/repos/{owner}/{repo}/missing", "404")))
+	assert.Equal(t, 4321.0, testutil.ToFloat64(m.rateLimitRemaining.WithLabelValues("core")))
+	assert.Equal(t, 5000.0, testutil.ToFloat64(m.rateLimitLimit.WithLabelValues("core")))
+}
+
+func Test_ToolMiddleware(t *testing.T) {
+	m := New()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	hooks := &server.Hooks{}
+	m.AddHooks(hooks)
+	middleware := m.ToolMiddleware(func(tool string) string {
+		if tool == "get_me" {
+			return "context"
+		}
+		return ""
+	})
+
+	ctx := context.Background()
+	call := func(name string, result *mcp.CallToolResult, err error) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = name
+		_, _ = middleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			now = now.Add(time.Second)
+			return result, err
+		})(ctx, request)
+	}
+
+	call("get_me", mcp.NewToolResultText("ok"), nil)
+	call("get_me", mcp.NewToolResultError("not found"), nil)
+	call("get_me", nil, errors.New("boom"))
+	call("session_tool", mcp.NewToolResultText("ok"), nil)
+
+	// Calls of tools the server does not have never reach the middleware
+	for _, name := range []string{"no_such_tool_1", "no_such_tool_2"} {
+		request := &mcp.CallToolRequest{}
+		request.Params.Name = name
+		for _, hook := range hooks.OnError {
+			hook(ctx, 1, mcp.MethodToolsCall, request, fmt.Errorf("tool '%s' not found: %w", name, server.ErrToolNotFound))
+		}
+	}
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.toolCalls.WithLabelValues("get_me", "context", "success")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.toolCalls.WithLabelValues("get_me", "context", "error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.toolCalls.WithLabelValues("unknown", "", "success")), "names of unknown tools should not become labels")
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.toolCalls.WithLabelValues("unknown", "", "error")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.toolDuration))
+
+	// The metrics are exposed over HTTP
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `github_mcp_tool_calls_total{result="success",tool="get_me",toolset="context"} 1`)
+	assert.Contains(t, rec.Body.String(), `github_mcp_tool_call_duration_seconds_sum{tool="get_me",toolset="context"} 3`)
+}
@@ -0,0 +1,144 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// searchTools are the tools that search repositories, with their query argument.
+var searchTools = map[string]string{
+	"search_repositories":  "query",
+	"search_code":          "query",
+	"search_issues":        "query",
+	"search_pull_requests": "query",
+}
+
+// unscopedTools are the tools that can access every repository of the authenticated user, unless
+// they are given both an owner and a repo to work on.
+var unscopedTools = map[string]bool{
+	"list_notifications":               true,
+	"mark_all_notifications_read":      true,
+	"get_notification_details":         true,
+	"dismiss_notification":             true,
+	"manage_notification_subscription": true,
+}
+
+// ToolMiddleware returns a middleware that rejects tool calls targeting repositories the policy
+// does not allow, and scopes the queries of the search tools. Tools for which isWrite returns true
+// are checked against the write rules. login returns the GitHub login a call is made as, which
+// owns the repositories created by create_repository and fork_repository.
+func (p *Policy) ToolMiddleware(isWrite func(tool string) bool, login func(ctx context.Context) string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			access := Read
+			if isWrite(request.Params.Name) {
+				access = Write
+			}
+
+			arguments := request.GetArguments()
+			if err := p.checkTool(ctx, access, request.Params.Name, arguments, login); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			if argument, ok := searchTools[request.Params.Name]; ok {
+				query, _ := arguments[argument].(string)
+				scoped, err := p.ScopeQuery(access, query)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				arguments = maps.Clone(arguments)
+				arguments[argument] = scoped
+				request.Params.Arguments = arguments
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// checkTool checks the repositories targeted by the owner and repo arguments of a tool call.
+// Calls with only an owner need access to all of its repositories. Calls of unscoped tools
+// without both are rejected if the policy has rules, as they could reach any repository.
+func (p *Policy) checkTool(ctx context.Context, access Access, tool string, arguments map[string]any, login func(ctx context.Context) string) error {
+	owner, _ := arguments["owner"].(string)
+	repo, _ := arguments["repo"].(string)
+
+	switch tool {
+	case "create_repository":
+		// Repositories are created for the authenticated user
+		name, _ := arguments["name"].(string)
+		return p.checkNewRepository(ctx, access, "", name, login)
+	case "fork_repository":
+		if err := p.Check(Read, owner, repo); err != nil {
+			return err
+		}
+		organization, _ := arguments["organization"].(string)
+		return p.checkNewRepository(ctx, access, organization, repo, login)
+	}
+
+	if unscopedTools[tool] && (owner == "" || repo == "") && p.restricts(access) {
+		return fmt.Errorf("repository policy cannot be applied to %s without an owner and a repo, as it may access any repository", tool)
+	}
+
+	switch {
+	case owner != "" && repo != "":
+		return p.Check(access, owner, repo)
+	case owner != "":
+		return p.CheckOwner(access, owner)
+	}
+	return nil
+}
+
+// checkNewRepository checks a repository that is about to be created in owner, or for the
+// authenticated user if owner is empty.
+func (p *Policy) checkNewRepository(ctx context.Context, access Access, owner, repo string, login func(ctx context.Context) string) error {
+	if owner == "" {
+		owner = login(ctx)
+	}
+	if owner == "" {
+		return errors.New("repository policy cannot be applied, as the authenticated user is unknown")
+	}
+	if repo == "" {
+		return errors.New("repository policy cannot be applied, as the repository name is missing")
+	}
+	return p.Check(access, owner, repo)
+}
+
+// ResourceTemplateMiddleware rejects reads of repository resources, such as
+// repo://{owner}/{repo}/contents{/path*}, that the read rules do not allow.
+func (p *Policy) ResourceTemplateMiddleware(next server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		owner := resourceArgument(request, "owner")
+		repo := resourceArgument(request, "repo")
+		switch {
+		case owner != "" && repo != "":
+			if err := p.Check(Read, owner, repo); err != nil {
+				return nil, err
+			}
+		case owner != "":
+			if err := p.CheckOwner(Read, owner); err != nil {
+				return nil, err
+			}
+		}
+		return next(ctx, request)
+	}
+}
+
+// resourceArgument returns a variable of a resource template, which the template matcher gives as
+// a slice with a single element.
+func resourceArgument(request mcp.ReadResourceRequest, name string) string {
+	switch value := request.Params.Arguments[name].(type) {
+	case []string:
+		if len(value) > 0 {
+			return value[0]
+		}
+	case string:
+		return value
+	}
+	return ""
+}
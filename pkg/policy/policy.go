@@ -0,0 +1,249 @@
+// Package policy restricts the repositories the server's tools and resources can access.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Access is the kind of access a tool call needs to a repository.
+type Access string
+
+const (
+	Read  Access = "read"
+	Write Access = "write"
+)
+
+// Rules decide which repositories can be accessed. Patterns are "owner/repo" globs matched case
+// insensitively, like "octo-org/*" or "octo-org/service-*".
+type Rules struct {
+	// Allow lists the repositories that can be accessed, every repository if empty
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny lists repositories that cannot be accessed even if they are allowed
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Policy holds the rules for read tools and write tools, which are applied separately so that a
+// repository can be readable without being writable.
+type Policy struct {
+	Read  Rules `json:"read"`
+	Write Rules `json:"write"`
+}
+
+// Load reads a JSON policy file.
+func Load(filename string) (*Policy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository policy: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var p Policy
+	if err := decoder.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to parse repository policy %s: %w", filename, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid repository policy %s: %w", filename, err)
+	}
+	return &p, nil
+}
+
+// Validate checks that every pattern is a valid "owner/repo" glob.
+func (p *Policy) Validate() error {
+	for _, rules := range []Rules{p.Read, p.Write} {
+		for _, pattern := range append(append([]string{}, rules.Allow...), rules.Deny...) {
+			if err := validatePattern(pattern); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validatePattern(pattern string) error {
+	ownerPattern, repoPattern, found := strings.Cut(pattern, "/")
+	if !found || ownerPattern == "" || repoPattern == "" || strings.Contains(repoPattern, "/") {
+		return fmt.Errorf("pattern %q must have the form owner/repo", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("pattern %q is malformed: %w", pattern, err)
+	}
+	return nil
+}
+
+// DeniedError is returned for repositories the policy does not allow access to.
+type DeniedError struct {
+	Access Access
+	// Repository is "owner/repo", or "owner/*" when every repository of an owner was requested
+	Repository string
+	// Pattern is the deny pattern that matched, if any
+	Pattern string
+}
+
+func (e *DeniedError) Error() string {
+	if e.Pattern != "" {
+		return fmt.Sprintf("repository policy denies %s access to %s (matched %q)", e.Access, e.Repository, e.Pattern)
+	}
+	return fmt.Sprintf("repository policy does not allow %s access to %s", e.Access, e.Repository)
+}
+
+func (p *Policy) rules(access Access) Rules {
+	if access == Write {
+		return p.Write
+	}
+	return p.Read
+}
+
+// restricts reports whether the policy has rules for the access.
+func (p *Policy) restricts(access Access) bool {
+	rules := p.rules(access)
+	return len(rules.Allow) > 0 || len(rules.Deny) > 0
+}
+
+// Check returns a *DeniedError unless access to the repository is allowed.
+func (p *Policy) Check(access Access, owner, repo string) error {
+	rules := p.rules(access)
+	name := strings.ToLower(owner + "/" + repo)
+
+	for _, pattern := range rules.Deny {
+		if matches(pattern, name) {
+			return &DeniedError{Access: access, Repository: owner + "/" + repo, Pattern: pattern}
+		}
+	}
+	if len(rules.Allow) == 0 {
+		return nil
+	}
+	for _, pattern := range rules.Allow {
+		if matches(pattern, name) {
+			return nil
+		}
+	}
+	return &DeniedError{Access: access, Repository: owner + "/" + repo}
+}
+
+// CheckOwner returns a *DeniedError unless access to every repository of owner is allowed.
+func (p *Policy) CheckOwner(access Access, owner string) error {
+	rules := p.rules(access)
+	owner = strings.ToLower(owner)
+
+	for _, pattern := range rules.Deny {
+		ownerPattern, _, _ := strings.Cut(pattern, "/")
+		if matches(ownerPattern, owner) {
+			return &DeniedError{Access: access, Repository: owner + "/*", Pattern: pattern}
+		}
+	}
+	if len(rules.Allow) == 0 {
+		return nil
+	}
+	for _, pattern := range rules.Allow {
+		ownerPattern, repoPattern, _ := strings.Cut(pattern, "/")
+		if repoPattern == "*" && matches(ownerPattern, owner) {
+			return nil
+		}
+	}
+	return &DeniedError{Access: access, Repository: owner + "/*"}
+}
+
+func matches(pattern, name string) bool {
+	matched, _ := path.Match(strings.ToLower(pattern), name)
+	return matched
+}
+
+// scopeQualifiers are the search qualifiers that select the repositories searched.
+var scopeQualifiers = []string{"repo:", "user:", "org:", "owner:"}
+
+// ScopeQuery restricts a search query to the repositories the policy allows access to, by adding
+// repo: and user: qualifiers. Queries that already select repositories with repo:, user:, org:
+// or owner: qualifiers are checked against the policy instead. Patterns with wildcards other than
+// a whole repository name cannot be expressed as qualifiers, so an error is returned if the query
+// would need them.
+//
+// Grouping and boolean operators can widen what such qualifiers select, as repository qualifiers
+// combine with OR, so queries using them alongside these qualifiers are rejected.
+func (p *Policy) ScopeQuery(access Access, query string) (string, error) {
+	rules := p.rules(access)
+
+	terms := strings.Fields(query)
+	operators := strings.ContainsAny(query, "()") || slices.ContainsFunc(terms, func(term string) bool {
+		return term == "OR" || term == "NOT"
+	})
+	scoped := false
+	for _, term := range terms {
+		term = strings.TrimLeft(term, "(")
+		excluded := strings.HasPrefix(term, "-")
+		term = strings.Trim(strings.TrimPrefix(term, "-"), "()")
+		for _, qualifier := range scopeQualifiers {
+			if len(term) <= len(qualifier) || !strings.EqualFold(term[:len(qualifier)], qualifier) {
+				continue
+			}
+			if operators {
+				return "", fmt.Errorf("search queries with %s qualifiers cannot use parentheses, OR or NOT under the repository policy", strings.TrimSuffix(qualifier, ":"))
+			}
+			if excluded {
+				// Excluding repositories can only narrow the search
+				continue
+			}
+			value := strings.Trim(term[len(qualifier):], `"`)
+			var err error
+			if qualifier == "repo:" {
+				owner, repo, _ := strings.Cut(value, "/")
+				err = p.Check(access, owner, repo)
+			} else {
+				err = p.CheckOwner(access, value)
+			}
+			if err != nil {
+				return "", err
+			}
+			scoped = true
+		}
+	}
+
+	if scoped {
+		// Every repository the query selects was checked against both allow and deny patterns
+		return query, nil
+	}
+
+	var qualifiers []string
+	for _, pattern := range rules.Allow {
+		qualifier, err := searchQualifier(pattern)
+		if err != nil {
+			return "", err
+		}
+		qualifiers = append(qualifiers, qualifier)
+	}
+	for _, pattern := range rules.Deny {
+		qualifier, err := searchQualifier(pattern)
+		if err != nil {
+			return "", err
+		}
+		qualifiers = append(qualifiers, "-"+qualifier)
+	}
+	if len(qualifiers) == 0 {
+		return query, nil
+	}
+	if operators {
+		// The qualifiers must apply to every alternative of the query
+		query = "(" + query + ")"
+	}
+	return strings.Join(append(qualifiers, query), " "), nil
+}
+
+// searchQualifier returns the search qualifier selecting the repositories matched by pattern.
+func searchQualifier(pattern string) (string, error) {
+	ownerPattern, repoPattern, _ := strings.Cut(pattern, "/")
+	switch {
+	case strings.ContainsAny(ownerPattern, `*?[\`):
+	case repoPattern == "*":
+		return "user:" + ownerPattern, nil
+	case !strings.ContainsAny(repoPattern, `*?[\`):
+		return "repo:" + pattern, nil
+	}
+	return "", fmt.Errorf("search is unavailable because repository policy pattern %q cannot be expressed as a search qualifier, search a specific repository with repo:owner/name instead", pattern)
+}
@@ -0,0 +1,296 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPolicy() *Policy {
+	return &Policy{
+		Read: Rules{
+			Allow: []string{"octo-org/*", "octocat/hello-world"},
+			Deny:  []string{"octo-org/secrets"},
+		},
+		Write: Rules{
+			Allow: []string{"octo-org/sandbox-*", "octocat/*"},
+		},
+	}
+}
+
+func Test_Check(t *testing.T) {
+	p := testPolicy()
+
+	tests := []struct {
+		access  Access
+		repo    string
+		allowed bool
+	}{
+		{Read, "octo-org/service", true},
+		{Read, "Octo-Org/Service", true},
+		{Read, "octo-org/secrets", false},
+		{Read, "octocat/hello-world", true},
+		{Read, "octocat/other", false},
+		{Read, "github/docs", false},
+		{Write, "octo-org/sandbox-1", true},
+		{Write, "octo-org/service", false},
+		{Write, "octocat/other", true},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.access)+" "+tc.repo, func(t *testing.T) {
+			owner, repo, _ := strings.Cut(tc.repo, "/")
+			err := p.Check(tc.access, owner, repo)
+			if tc.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			var denied *DeniedError
+			require.ErrorAs(t, err, &denied)
+			assert.Equal(t, tc.access, denied.Access)
+			assert.Equal(t, tc.repo, denied.Repository)
+		})
+	}
+
+	assert.EqualError(t, p.Check(Read, "octo-org", "secrets"), `repository policy denies read access to octo-org/secrets (matched "octo-org/secrets")`)
+	assert.EqualError(t, p.Check(Write, "github", "docs"), "repository policy does not allow write access to github/docs")
+
+	// An empty policy allows everything
+	assert.NoError(t, (&Policy{}).Check(Write, "github", "docs"))
+}
+
+func Test_CheckOwner(t *testing.T) {
+	p := testPolicy()
+
+	// A single denied repository rules out all repositories of its owner
+	assert.Error(t, p.CheckOwner(Read, "octo-org"))
+	assert.Error(t, p.CheckOwner(Read, "github"))
+	assert.NoError(t, p.CheckOwner(Write, "octocat"))
+	assert.Error(t, p.CheckOwner(Write, "octo-org"))
+}
+
+func Test_ScopeQuery(t *testing.T) {
+	p := testPolicy()
+
+	tests := []struct {
+		name     string
+		policy   *Policy
+		access   Access
+		query    string
+		expected string
+		err      string
+	}{
+		{
+			name:     "unscoped query is scoped to the allowed repositories",
+			policy:   p,
+			access:   Read,
+			query:    "bug in:title",
+			expected: "user:octo-org repo:octocat/hello-world -repo:octo-org/secrets bug in:title",
+		},
+		{
+			name:     "query scoped to an allowed repository is kept",
+			policy:   p,
+			access:   Read,
+			query:    "repo:octo-org/service bug",
+			expected: "repo:octo-org/service bug",
+		},
+		{
+			name:   "query scoped to a denied repository is rejected",
+			policy: p,
+			access: Read,
+			query:  "bug REPO:octo-org/secrets",
+			err:    `repository policy denies read access to octo-org/secrets (matched "octo-org/secrets")`,
+		},
+		{
+			name:   "query scoped to an owner with a denied repository is rejected",
+			policy: p,
+			access: Read,
+			query:  "org:octo-org bug",
+			err:    `repository policy denies read access to octo-org/* (matched "octo-org/secrets")`,
+		},
+		{
+			name:     "exclusions are kept",
+			policy:   p,
+			access:   Read,
+			query:    "-repo:github/docs bug",
+			expected: "user:octo-org repo:octocat/hello-world -repo:octo-org/secrets -repo:github/docs bug",
+		},
+		{
+			name:   "qualifier in a group is rejected",
+			policy: p,
+			access: Read,
+			query:  "bug (repo:octo-org/secrets)",
+			err:    "search queries with repo qualifiers cannot use parentheses, OR or NOT under the repository policy",
+		},
+		{
+			name:   "qualifier after OR is rejected",
+			policy: p,
+			access: Read,
+			query:  "repo:octo-org/service OR repo:octo-org/secrets",
+			err:    "search queries with repo qualifiers cannot use parentheses, OR or NOT under the repository policy",
+		},
+		{
+			name:   "negated qualifier is rejected",
+			policy: p,
+			access: Read,
+			query:  "bug NOT -org:github",
+			err:    "search queries with org qualifiers cannot use parentheses, OR or NOT under the repository policy",
+		},
+		{
+			name:     "unscoped query with OR is grouped",
+			policy:   p,
+			access:   Read,
+			query:    "bug OR crash",
+			expected: "user:octo-org repo:octocat/hello-world -repo:octo-org/secrets (bug OR crash)",
+		},
+		{
+			name:   "partial wildcards cannot be expressed",
+			policy: p,
+			access: Write,
+			query:  "bug",
+			err:    `search is unavailable because repository policy pattern "octo-org/sandbox-*" cannot be expressed as a search qualifier, search a specific repository with repo:owner/name instead`,
+		},
+		{
+			name:     "partial wildcards are fine when the query is scoped",
+			policy:   p,
+			access:   Write,
+			query:    "repo:octo-org/sandbox-2 bug",
+			expected: "repo:octo-org/sandbox-2 bug",
+		},
+		{
+			name:     "empty policy leaves the query alone",
+			policy:   &Policy{},
+			access:   Read,
+			query:    "bug",
+			expected: "bug",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.policy.ScopeQuery(tc.access, tc.query)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, query)
+		})
+	}
+}
+
+func Test_Load(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	p, err := Load(write("policy.json", `{"read": {"allow": ["octo-org/*"]}, "write": {"deny": ["*/*"]}}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Policy{Read: Rules{Allow: []string{"octo-org/*"}}, Write: Rules{Deny: []string{"*/*"}}}, p)
+
+	_, err = Load(write("unknown.json", `{"read": {"allowed": ["octo-org/*"]}}`))
+	assert.ErrorContains(t, err, `unknown field "allowed"`)
+
+	_, err = Load(write("owner.json", `{"read": {"allow": ["octo-org"]}}`))
+	assert.ErrorContains(t, err, `pattern "octo-org" must have the form owner/repo`)
+
+	_, err = Load(write("malformed.json", `{"write": {"deny": ["octo-org/[a-"]}}`))
+	assert.ErrorContains(t, err, `pattern "octo-org/[a-" is malformed`)
+}
+
+func callTool(t *testing.T, p *Policy, name string, arguments map[string]any) (*mcp.CallToolResult, map[string]any) {
+	t.Helper()
+	var received map[string]any
+	handler := p.ToolMiddleware(
+		func(tool string) bool { return tool != "get_file_contents" && tool != "search_code" },
+		func(_ context.Context) string { return "octocat" },
+	)(func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		received = request.GetArguments()
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+	result, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	return result, received
+}
+
+func Test_ToolMiddleware(t *testing.T) {
+	p := testPolicy()
+
+	result, received := callTool(t, p, "get_file_contents", map[string]any{"owner": "octo-org", "repo": "service", "path": "README.md"})
+	assert.False(t, result.IsError)
+	assert.NotNil(t, received)
+
+	result, received = callTool(t, p, "create_issue", map[string]any{"owner": "octo-org", "repo": "service", "title": "Bug"})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "repository policy does not allow write access to octo-org/service", getTextResult(t, result))
+	assert.Nil(t, received, "denied calls should not reach the tool")
+
+	result, _ = callTool(t, p, "create_repository", map[string]any{"name": "new-repo"})
+	assert.False(t, result.IsError, "repositories are created for the authenticated user")
+
+	result, _ = callTool(t, p, "fork_repository", map[string]any{"owner": "octo-org", "repo": "service"})
+	assert.False(t, result.IsError, "forks are created for the authenticated user")
+
+	result, _ = callTool(t, p, "fork_repository", map[string]any{"owner": "octo-org", "repo": "service", "organization": "octo-org"})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "repository policy does not allow write access to octo-org/service", getTextResult(t, result))
+
+	result, _ = callTool(t, p, "list_notifications", map[string]any{"owner": "octo-org"})
+	assert.True(t, result.IsError, "calls with only an owner need access to all of its repositories")
+
+	result, received = callTool(t, p, "get_notification_details", map[string]any{"notificationID": "1"})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "repository policy cannot be applied to get_notification_details without an owner and a repo, as it may access any repository", getTextResult(t, result))
+	assert.Nil(t, received, "notifications of any repository could be read")
+
+	result, _ = callTool(t, p, "mark_all_notifications_read", map[string]any{"owner": "octocat"})
+	assert.True(t, result.IsError, "only owner is ignored, so every notification would be marked")
+
+	result, _ = callTool(t, p, "mark_all_notifications_read", map[string]any{"owner": "octocat", "repo": "hello-world"})
+	assert.False(t, result.IsError)
+
+	result, _ = callTool(t, &Policy{Read: testPolicy().Read}, "dismiss_notification", map[string]any{"threadID": "1"})
+	assert.False(t, result.IsError, "the policy has no write rules")
+
+	arguments := map[string]any{"query": "fmt.Println"}
+	result, received = callTool(t, p, "search_code", arguments)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "user:octo-org repo:octocat/hello-world -repo:octo-org/secrets fmt.Println", received["query"])
+	assert.Equal(t, "fmt.Println", arguments["query"], "the arguments of the request should not be modified")
+}
+
+func Test_ResourceTemplateMiddleware(t *testing.T) {
+	p := testPolicy()
+	handler := p.ResourceTemplateMiddleware(func(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{Text: "content"}}, nil
+	})
+
+	read := func(owner, repo string) error {
+		request := mcp.ReadResourceRequest{}
+		request.Params.Arguments = map[string]any{"owner": []string{owner}, "repo": []string{repo}}
+		_, err := handler(context.Background(), request)
+		return err
+	}
+
+	assert.NoError(t, read("octo-org", "service"))
+	assert.EqualError(t, read("octo-org", "secrets"), `repository policy denies read access to octo-org/secrets (matched "octo-org/secrets")`)
+}
+
+func getTextResult(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.Len(t, result.Content, 1)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
@@ -0,0 +1,307 @@
+// Package ratelimit provides an HTTP transport that reacts to GitHub's primary and secondary rate limits
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is the rate limit budget GitHub reports in the X-RateLimit-* headers of a response.
+type Rate struct {
+	Limit     int
+	Remaining int
+	Used      int
+	Reset     time.Time
+	Resource  string
+}
+
+// ParseRate reads the rate limit budget from the response headers, reporting false if they are absent.
+func ParseRate(h http.Header) (Rate, bool) {
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return Rate{}, false
+	}
+
+	rate := Rate{
+		Remaining: remaining,
+		Resource:  h.Get("X-RateLimit-Resource"),
+	}
+	rate.Limit, _ = strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	rate.Used, _ = strconv.Atoi(h.Get("X-RateLimit-Used"))
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rate.Reset = time.Unix(reset, 0)
+	}
+	return rate, true
+}
+
+// Options configures the retry behaviour of the Transport.
+type Options struct {
+	// MaxRetries is the maximum number of times a request is retried, zero disables retries
+	MaxRetries int
+
+	// MaxWait is the maximum total time spent waiting before retries of a single request,
+	// once a retry would exceed it the last response is returned as is
+	MaxWait time.Duration
+
+	// BaseBackoff is the initial backoff when the response does not say how long to wait,
+	// it doubles with every attempt
+	BaseBackoff time.Duration
+
+	// OnRate, if set, is called with the remaining budget reported by every response
+	OnRate func(req *http.Request, rate Rate)
+
+	// OnRetry, if set, is called before waiting to retry a request
+	OnRetry func(req *http.Request, resp *http.Response, attempt int, wait time.Duration)
+}
+
+const (
+	defaultBaseBackoff = time.Second
+	// secondaryRateLimitWait is how long GitHub recommends waiting after hitting a secondary
+	// rate limit that does not say how long to wait for
+	secondaryRateLimitWait = time.Minute
+)
+
+// Transport retries idempotent requests that were rejected because of rate limits, honouring
+// Retry-After and X-RateLimit-Reset with jittered exponential backoff. Requests that only read are
+// also retried after transient server errors.
+type Transport struct {
+	transport http.RoundTripper
+	opts      Options
+
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func() float64
+}
+
+// NewTransport wraps transport with rate limit aware retries.
+func NewTransport(transport http.RoundTripper, opts Options) *Transport {
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = defaultBaseBackoff
+	}
+	return &Transport{
+		transport: transport,
+		opts:      opts,
+		now:       time.Now,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+				return nil
+			}
+		},
+		jitter: rand.Float64,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retryable := t.opts.MaxRetries > 0 && isIdempotent(req)
+	write := !isSafe(req)
+
+	var waited time.Duration
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			// Bodies can only be read once, so replay them from a fresh copy
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.transport.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if rate, ok := ParseRate(resp.Header); ok && t.opts.OnRate != nil {
+			t.opts.OnRate(req, rate)
+		}
+
+		if !retryable || attempt >= t.opts.MaxRetries {
+			return resp, nil
+		}
+
+		wait, retry := t.retryAfter(req, resp, attempt, write)
+		if !retry || waited+wait > t.opts.MaxWait {
+			return resp, nil
+		}
+
+		if t.opts.OnRetry != nil {
+			t.opts.OnRetry(req, resp, attempt+1, wait)
+		}
+
+		// Drain the body so that the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		waited += wait
+	}
+}
+
+// retryAfter decides whether a response should be retried, and how long to wait before doing so.
+// Writes are only retried when the rate limit headers say that they were rejected, as a gateway
+// error does not tell whether the write was made.
+func (t *Transport) retryAfter(req *http.Request, resp *http.Response, attempt int, write bool) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// GraphQL reports an exhausted primary rate limit in the errors of a successful response
+		if !strings.HasSuffix(req.URL.Path, "/graphql") {
+			return 0, false
+		}
+		rate, ok := ParseRate(resp.Header)
+		if ok && rate.Remaining > 0 || !isGraphQLRateLimited(resp) {
+			return 0, false
+		}
+		if ok && !rate.Reset.IsZero() {
+			return max(rate.Reset.Sub(t.now()), 0) + t.smallJitter(), true
+		}
+		return t.backoff(attempt), true
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		// Secondary rate limits say how long to wait with Retry-After
+		if wait, ok := t.parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait + t.smallJitter(), true
+		}
+
+		// Primary rate limits are exhausted until the reset time
+		if rate, ok := ParseRate(resp.Header); ok && rate.Remaining == 0 && !rate.Reset.IsZero() {
+			return max(rate.Reset.Sub(t.now()), 0) + t.smallJitter(), true
+		}
+
+		if write {
+			return 0, false
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return t.backoff(attempt), true
+		}
+		if isSecondaryRateLimit(resp) {
+			return max(secondaryRateLimitWait, t.backoff(attempt)), true
+		}
+		// Any other 403 is a permission problem, which retrying won't fix
+		return 0, false
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if write {
+			return 0, false
+		}
+		return t.backoff(attempt), true
+	default:
+		return 0, false
+	}
+}
+
+func (t *Transport) parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(t.now()), 0), true
+	}
+	return 0, false
+}
+
+// backoff returns an exponential backoff with full jitter for the attempt.
+func (t *Transport) backoff(attempt int) time.Duration {
+	ceiling := t.opts.BaseBackoff << attempt
+	return time.Duration(t.jitter() * float64(ceiling))
+}
+
+// smallJitter spreads out retries of clients that were told to wait for the same time.
+func (t *Transport) smallJitter() time.Duration {
+	return time.Duration(t.jitter() * float64(time.Second))
+}
+
+// isSecondaryRateLimit checks the body of a 403 for GitHub's secondary rate limit message,
+// restoring the body so that it can still be read by the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit"))
+}
+
+// isGraphQLRateLimited checks the body of a GraphQL response for a RATE_LIMITED error, restoring
+// the body so that it can still be read by the caller.
+func isGraphQLRateLimited(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	var payload struct {
+		Errors []struct {
+			Type string `json:"type"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return false
+	}
+	for _, e := range payload.Errors {
+		if e.Type == "RATE_LIMITED" {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdempotent reports whether the request can be sent again with the same effect, which is the
+// case for the idempotent HTTP methods and for the requests isSafe accepts.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.GetBody != nil
+	default:
+		return isSafe(req)
+	}
+}
+
+// isSafe reports whether the request only reads. Besides the safe HTTP methods, this includes
+// GraphQL queries, which are sent as POST requests.
+func isSafe(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return req.Body == nil || req.GetBody != nil
+	case http.MethodPost:
+		return isGraphQLQuery(req)
+	default:
+		return false
+	}
+}
+
+func isGraphQLQuery(req *http.Request) bool {
+	if req.GetBody == nil || !strings.HasSuffix(req.URL.Path, "/graphql") {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	defer func() { _ = body.Close() }()
+
+	var payload struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(body).Decode(&payload); err != nil {
+		return false
+	}
+	return !strings.HasPrefix(strings.TrimSpace(payload.Query), "mutation")
+}
@@ -0,0 +1,340 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedServer answers requests with the given handlers in order, and with 200 OK once they run out.
+func scriptedServer(t *testing.T, handlers ...http.HandlerFunc) (*httptest.Server, *int) {
+	t.Helper()
+
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= len(handlers) {
+			handlers[calls-1](w, r)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &calls
+}
+
+// testTransport returns a transport that records waits instead of sleeping.
+func testTransport(opts Options, now time.Time, waits *[]time.Duration) *Transport {
+	transport := NewTransport(http.DefaultTransport, opts)
+	transport.now = func() time.Time { return now }
+	transport.jitter = func() float64 { return 0.5 }
+	transport.sleep = func(_ context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return transport
+}
+
+func Test_Transport_RetryAfter(t *testing.T) {
+	ts, calls := scriptedServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+	})
+
+	var waits []time.Duration
+	transport := testTransport(Options{MaxRetries: 3, MaxWait: time.Minute}, time.Now(), &waits)
+
+	resp, err := (&http.Client{Transport: transport}).Get(ts.URL + "/repos/octo/repo")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, []time.Duration{3*time.Second + 500*time.Millisecond}, waits)
+}
+
+func Test_Transport_PrimaryRateLimitReset(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ts, calls := scriptedServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(10*time.Second).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	var waits []time.Duration
+	var rates []Rate
+	transport := testTransport(Options{
+		MaxRetries: 3,
+		MaxWait:    time.Minute,
+		OnRate:     func(_ *http.Request, rate Rate) { rates = append(rates, rate) },
+	}, now, &waits)
+
+	resp, err := (&http.Client{Transport: transport}).Get(ts.URL + "/repos/octo/repo")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, []time.Duration{10*time.Second + 500*time.Millisecond}, waits)
+
+	require.Len(t, rates, 2)
+	assert.Equal(t, 0, rates[0].Remaining)
+	assert.Equal(t, 4999, rates[1].Remaining)
+}
+
+func Test_Transport_GivesUpAfterMaxWait(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ts, calls := scriptedServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(30*time.Minute).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+	})
+
+	var waits []time.Duration
+	transport := testTransport(Options{MaxRetries: 3, MaxWait: time.Minute}, now, &waits)
+
+	resp, err := (&http.Client{Transport: transport}).Get(ts.URL + "/repos/octo/repo")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "the rate limit error should be returned as is")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "API rate limit exceeded")
+	assert.Equal(t, 1, *calls)
+	assert.Empty(t, waits)
+}
+
+func Test_Transport_ExponentialBackoff(t *testing.T) {
+	unavailable := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	ts, calls := scriptedServer(t, unavailable, unavailable, unavailable)
+
+	var waits []time.Duration
+	transport := testTransport(Options{MaxRetries: 3, MaxWait: time.Minute, BaseBackoff: time.Second}, time.Now(), &waits)
+
+	resp, err := (&http.Client{Transport: transport}).Get(ts.URL + "/repos/octo/repo")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 4, *calls)
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second}, waits)
+}
+
+func Test_Transport_DoesNotRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		status  int
+		headers map[string]string
+	}{
+		{
+			name:   "permission denied",
+			method: http.MethodGet,
+			path:   "/repos/octo/repo",
+			status: http.StatusForbidden,
+		},
+		{
+			name:    "non-idempotent REST request",
+			method:  http.MethodPost,
+			path:    "/repos/octo/repo/issues",
+			body:    `{"title":"bug"}`,
+			status:  http.StatusTooManyRequests,
+			headers: map[string]string{"Retry-After": "1"},
+		},
+		{
+			name:    "GraphQL mutation",
+			method:  http.MethodPost,
+			path:    "/graphql",
+			body:    `{"query":"mutation($input:AddCommentInput!){addComment(input:$input){clientMutationId}}"}`,
+			status:  http.StatusTooManyRequests,
+			headers: map[string]string{"Retry-After": "1"},
+		},
+		{
+			name:   "write after a gateway error",
+			method: http.MethodPut,
+			path:   "/repos/octo/repo/contents/README.md",
+			body:   `{"message":"update","content":"aGVsbG8="}`,
+			status: http.StatusBadGateway,
+		},
+		{
+			name:   "write after a 429 without rate limit headers",
+			method: http.MethodDelete,
+			path:   "/repos/octo/repo/git/refs/heads/feature",
+			status: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts, calls := scriptedServer(t, func(w http.ResponseWriter, _ *http.Request) {
+				for k, v := range tc.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tc.status)
+			})
+
+			var waits []time.Duration
+			transport := testTransport(Options{MaxRetries: 3, MaxWait: time.Minute}, time.Now(), &waits)
+
+			req, err := http.NewRequest(tc.method, ts.URL+tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.Equal(t, 1, *calls)
+			assert.Empty(t, waits)
+		})
+	}
+}
+
+func Test_Transport_RetriesRateLimitedWrite(t *testing.T) {
+	ts, calls := scriptedServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	var waits []time.Duration
+	transport := testTransport(Options{MaxRetries: 3, MaxWait: time.Minute}, time.Now(), &waits)
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL+"/repos/octo/repo/contents/README.md", strings.NewReader(`{"message":"update"}`))
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, []time.Duration{2*time.Second + 500*time.Millisecond}, waits)
+}
+
+func Test_Transport_RetriesGraphQLQuery(t *testing.T) {
+	var bodies []string
+	record := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}
+	ts, calls := scriptedServer(t, record)
+
+	var waits []time.Duration
+	transport := testTransport(Options{MaxRetries: 3, MaxWait: time.Minute}, time.Now(), &waits)
+
+	query := `{"query":"query($owner:String!){repository(owner:$owner){id}}"}`
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/graphql", strings.NewReader(query))
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, []string{query}, bodies)
+}
+
+func Test_Transport_GraphQLRateLimited(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	rateLimited := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(20*time.Second).Unix(), 10))
+		_, _ = w.Write([]byte(`{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded for user ID 1."}]}`))
+	}
+	query := `{"query":"query($owner:String!){repository(owner:$owner){id}}"}`
+
+	t.Run("waits until the reset", func(t *testing.T) {
+		ts, calls := scriptedServer(t, rateLimited)
+		var waits []time.Duration
+		transport := testTransport(Options{MaxRetries: 3, MaxWait: time.Minute}, now, &waits)
+
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/graphql", strings.NewReader(query))
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: transport}).Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"ok":true}`, string(body))
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, []time.Duration{20*time.Second + 500*time.Millisecond}, waits)
+	})
+
+	t.Run("returns the error after max wait", func(t *testing.T) {
+		ts, calls := scriptedServer(t, rateLimited)
+		var waits []time.Duration
+		transport := testTransport(Options{MaxRetries: 3, MaxWait: 10 * time.Second}, now, &waits)
+
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/graphql", strings.NewReader(query))
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: transport}).Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "RATE_LIMITED", "the body is restored after it was checked")
+		assert.Equal(t, 1, *calls)
+		assert.Empty(t, waits)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		ts, calls := scriptedServer(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			_, _ = w.Write([]byte(`{"errors":[{"type":"NOT_FOUND","message":"Could not resolve to a Repository."}]}`))
+		})
+		var waits []time.Duration
+		transport := testTransport(Options{MaxRetries: 3, MaxWait: time.Minute}, now, &waits)
+
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/graphql", strings.NewReader(query))
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: transport}).Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, 1, *calls)
+		assert.Empty(t, waits)
+	})
+}
+
+func Test_ParseRate(t *testing.T) {
+	h := http.Header{}
+	_, ok := ParseRate(h)
+	assert.False(t, ok)
+
+	h.Set("X-RateLimit-Limit", "5000")
+	h.Set("X-RateLimit-Remaining", "42")
+	h.Set("X-RateLimit-Used", "4958")
+	h.Set("X-RateLimit-Reset", "1735732800")
+	h.Set("X-RateLimit-Resource", "core")
+
+	rate, ok := ParseRate(h)
+	require.True(t, ok)
+	assert.Equal(t, Rate{
+		Limit:     5000,
+		Remaining: 42,
+		Used:      4958,
+		Reset:     time.Unix(1735732800, 0),
+		Resource:  "core",
+	}, rate)
+}
@@ -2,6 +2,9 @@ package toolsets
 
 import (
 	"fmt"
+	"maps"
+	"strconv"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -29,8 +32,128 @@ func NewToolsetDoesNotExistError(name string) *ToolsetDoesNotExistError {
 	return &ToolsetDoesNotExistError{Name: name}
 }
 
-func NewServerTool(tool mcp.Tool, handler server.ToolHandlerFunc) server.ServerTool {
-	return server.ServerTool{Tool: tool, Handler: handler}
+type ToolDoesNotExistError struct {
+	Name string
+}
+
+func (e *ToolDoesNotExistError) Error() string {
+	return fmt.Sprintf("tool %s does not exist", e.Name)
+}
+
+func (e *ToolDoesNotExistError) Is(target error) bool {
+	if target == nil {
+		return false
+	}
+	if _, ok := target.(*ToolDoesNotExistError); ok {
+		return true
+	}
+	return false
+}
+
+func NewToolDoesNotExistError(name string) *ToolDoesNotExistError {
+	return &ToolDoesNotExistError{Name: name}
+}
+
+// ToolUnsupportedError is returned for tools, or arguments of tools, that the GitHub Enterprise
+// Server release does not have.
+type ToolUnsupportedError struct {
+	Name string
+	// Argument is the argument the release does not have, empty if it does not have the tool
+	Argument string
+	// Version is the release of GitHub Enterprise Server
+	Version string
+	// MinVersion is the first release with the tool or argument, empty if no release has it
+	MinVersion string
+}
+
+func (e *ToolUnsupportedError) Error() string {
+	subject := "tool " + e.Name
+	if e.Argument != "" {
+		subject = fmt.Sprintf("argument %s of tool %s", e.Argument, e.Name)
+	}
+	if e.MinVersion == "" {
+		return fmt.Sprintf("%s is not available on GitHub Enterprise Server", subject)
+	}
+	return fmt.Sprintf("%s requires GitHub Enterprise Server %s or later, the server runs %s", subject, e.MinVersion, e.Version)
+}
+
+// ServerTool is a tool with its handler, and the releases of GitHub Enterprise Server that have it.
+type ServerTool struct {
+	server.ServerTool
+	// MinGHESVersion is the first release of GitHub Enterprise Server with the tool, empty if every release has it
+	MinGHESVersion string
+	// NotOnGHES is set for tools that no release of GitHub Enterprise Server has
+	NotOnGHES bool
+	// ArgumentMinGHESVersions holds the first release with each argument that is newer than the tool
+	ArgumentMinGHESVersions map[string]string
+}
+
+func NewServerTool(tool mcp.Tool, handler server.ToolHandlerFunc) ServerTool {
+	return ServerTool{ServerTool: server.ServerTool{Tool: tool, Handler: handler}}
+}
+
+// RequireGHESVersion returns the tool marked as only available from the release of GitHub
+// Enterprise Server on.
+func (t ServerTool) RequireGHESVersion(version string) ServerTool {
+	t.MinGHESVersion = version
+	return t
+}
+
+// UnavailableOnGHES returns the tool marked as not available on any release of GitHub Enterprise Server.
+func (t ServerTool) UnavailableOnGHES() ServerTool {
+	t.NotOnGHES = true
+	return t
+}
+
+// RequireGHESVersionForArguments returns the tool with the arguments marked as only available from
+// the release of GitHub Enterprise Server on, for arguments added to the API after the tool.
+func (t ServerTool) RequireGHESVersionForArguments(version string, arguments ...string) ServerTool {
+	versions := make(map[string]string, len(t.ArgumentMinGHESVersions)+len(arguments))
+	for argument, minVersion := range t.ArgumentMinGHESVersions {
+		versions[argument] = minVersion
+	}
+	for _, argument := range arguments {
+		versions[argument] = version
+	}
+	t.ArgumentMinGHESVersions = versions
+	return t
+}
+
+// CheckGHESVersion returns a *ToolUnsupportedError if the release of GitHub Enterprise Server does
+// not have the tool, or one of the arguments of a call. An empty version supports everything.
+func (t ServerTool) CheckGHESVersion(version string, arguments map[string]any) error {
+	if version == "" {
+		return nil
+	}
+	if t.NotOnGHES || (t.MinGHESVersion != "" && compareVersions(version, t.MinGHESVersion) < 0) {
+		return &ToolUnsupportedError{Name: t.Tool.Name, Version: version, MinVersion: t.MinGHESVersion}
+	}
+	for argument, minVersion := range t.ArgumentMinGHESVersions {
+		if _, ok := arguments[argument]; ok && compareVersions(version, minVersion) < 0 {
+			return &ToolUnsupportedError{Name: t.Tool.Name, Argument: argument, Version: version, MinVersion: minVersion}
+		}
+	}
+	return nil
+}
+
+// forGHESVersion returns the tool as offered for the release of GitHub Enterprise Server, without
+// the arguments the release does not have, and false if the release does not have the tool.
+func (t ServerTool) forGHESVersion(version string) (server.ServerTool, bool) {
+	if err := t.CheckGHESVersion(version, nil); err != nil {
+		return server.ServerTool{}, false
+	}
+	tool := t.ServerTool
+	if version == "" || len(t.ArgumentMinGHESVersions) == 0 {
+		return tool, true
+	}
+	// The properties of the registered tool are not changed, as other hosts may have the arguments
+	tool.Tool.InputSchema.Properties = maps.Clone(tool.Tool.InputSchema.Properties)
+	for argument, minVersion := range t.ArgumentMinGHESVersions {
+		if compareVersions(version, minVersion) < 0 {
+			delete(tool.Tool.InputSchema.Properties, argument)
+		}
+	}
+	return tool, true
 }
 
 func NewServerResourceTemplate(resourceTemplate mcp.ResourceTemplate, handler server.ResourceTemplateHandlerFunc) ServerResourceTemplate {
@@ -53,6 +176,10 @@ type ServerResourceTemplate struct {
 	handler          server.ResourceTemplateHandlerFunc
 }
 
+// ResourceTemplateHandlerMiddleware wraps the handler of a resource template, like
+// server.ToolHandlerMiddleware does for tools.
+type ResourceTemplateHandlerMiddleware func(server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc
+
 // ServerPrompt represents a prompt that can be registered with the MCP server.
 type ServerPrompt struct {
 	Prompt  mcp.Prompt
@@ -65,8 +192,12 @@ type Toolset struct {
 	Description string
 	Enabled     bool
 	readOnly    bool
-	writeTools  []server.ServerTool
-	readTools   []server.ServerTool
+	writeTools  []ServerTool
+	readTools   []ServerTool
+	// toolFilter, if set, decides which of the tools are offered
+	toolFilter func(name string) bool
+	// ghesVersion, if set, is the GitHub Enterprise Server release the tools are offered for
+	ghesVersion string
 	// resources are not tools, but the community seems to be moving towards namespaces as a broader concept
 	// and in order to have multiple servers running concurrently, we want to avoid overlapping resources too.
 	resourceTemplates []ServerResourceTemplate
@@ -76,33 +207,114 @@ type Toolset struct {
 
 func (t *Toolset) GetActiveTools() []server.ServerTool {
 	if t.Enabled {
-		if t.readOnly {
-			return t.readTools
-		}
-		return append(t.readTools, t.writeTools...)
+		return t.GetAvailableTools()
 	}
 	return nil
 }
 
 func (t *Toolset) GetAvailableTools() []server.ServerTool {
-	if t.readOnly {
-		return t.readTools
+	tools := t.readTools
+	if !t.readOnly {
+		tools = append(tools[:len(tools):len(tools)], t.writeTools...)
+	}
+
+	available := make([]server.ServerTool, 0, len(tools))
+	for _, tool := range tools {
+		if t.toolFilter != nil && !t.toolFilter(tool.Tool.Name) {
+			continue
+		}
+		if offered, ok := tool.forGHESVersion(t.ghesVersion); ok {
+			available = append(available, offered)
+		}
 	}
-	return append(t.readTools, t.writeTools...)
+	return available
 }
 
 func (t *Toolset) RegisterTools(s *server.MCPServer) {
 	if !t.Enabled {
 		return
 	}
-	for _, tool := range t.readTools {
+	for _, tool := range t.GetAvailableTools() {
 		s.AddTool(tool.Tool, tool.Handler)
 	}
-	if !t.readOnly {
-		for _, tool := range t.writeTools {
-			s.AddTool(tool.Tool, tool.Handler)
+}
+
+// SetToolFilter limits the tools of the toolset to those for which filter returns true.
+func (t *Toolset) SetToolFilter(filter func(name string) bool) {
+	t.toolFilter = filter
+}
+
+// SetGHESVersion hides the tools that the release of GitHub Enterprise Server does not have. An
+// empty version offers all tools, as for github.com.
+func (t *Toolset) SetGHESVersion(version string) {
+	t.ghesVersion = version
+}
+
+// CheckGHESVersion returns a *ToolUnsupportedError if the release of GitHub Enterprise Server does
+// not have the named tool, or one of the arguments of a call. Tools the toolset does not have are
+// supported, and so is everything with an empty version.
+func (t *Toolset) CheckGHESVersion(name string, arguments map[string]any, version string) error {
+	if tool, ok := t.tool(name); ok {
+		return tool.CheckGHESVersion(version, arguments)
+	}
+	return nil
+}
+
+// compareVersions compares dotted version numbers like 3.14.2, returning a negative number if a
+// is older than b, zero if they are the same and a positive number if a is newer. Anything after
+// the numbers, like a release candidate suffix, is ignored.
+func compareVersions(a, b string) int {
+	as, bs := versionNumbers(a), versionNumbers(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+func versionNumbers(version string) []int {
+	var numbers []int
+	for _, part := range strings.Split(version, ".") {
+		digits := part
+		if i := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+			digits = part[:i]
+		}
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, n)
+		if len(digits) < len(part) {
+			break
+		}
+	}
+	return numbers
+}
+
+// hasTool reports whether the toolset has a tool called name, regardless of filters and read-only mode.
+func (t *Toolset) hasTool(name string) bool {
+	_, ok := t.tool(name)
+	return ok
+}
+
+// tool returns the tool called name, regardless of filters and read-only mode.
+func (t *Toolset) tool(name string) (ServerTool, bool) {
+	for _, tools := range [][]ServerTool{t.readTools, t.writeTools} {
+		for _, tool := range tools {
+			if tool.Tool.Name == name {
+				return tool, true
+			}
 		}
 	}
+	return ServerTool{}, false
 }
 
 func (t *Toolset) AddResourceTemplates(templates ...ServerResourceTemplate) *Toolset {
@@ -126,12 +338,18 @@ func (t *Toolset) GetAvailableResourceTemplates() []ServerResourceTemplate {
 	return t.resourceTemplates
 }
 
-func (t *Toolset) RegisterResourcesTemplates(s *server.MCPServer) {
+// RegisterResourcesTemplates adds the resource templates of the toolset to s, with their handlers
+// wrapped by middlewares, the first of which is the outermost.
+func (t *Toolset) RegisterResourcesTemplates(s *server.MCPServer, middlewares ...ResourceTemplateHandlerMiddleware) {
 	if !t.Enabled {
 		return
 	}
 	for _, resource := range t.resourceTemplates {
-		s.AddResourceTemplate(resource.resourceTemplate, resource.handler)
+		handler := resource.handler
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			handler = middlewares[i](handler)
+		}
+		s.AddResourceTemplate(resource.resourceTemplate, handler)
 	}
 }
 
@@ -149,7 +367,7 @@ func (t *Toolset) SetReadOnly() {
 	t.readOnly = true
 }
 
-func (t *Toolset) AddWriteTools(tools ...server.ServerTool) *Toolset {
+func (t *Toolset) AddWriteTools(tools ...ServerTool) *Toolset {
 	// Silently ignore if the toolset is read-only to avoid any breach of that contract
 	for _, tool := range tools {
 		if *tool.Tool.Annotations.ReadOnlyHint {
@@ -162,7 +380,7 @@ func (t *Toolset) AddWriteTools(tools ...server.ServerTool) *Toolset {
 	return t
 }
 
-func (t *Toolset) AddReadTools(tools ...server.ServerTool) *Toolset {
+func (t *Toolset) AddReadTools(tools ...ServerTool) *Toolset {
 	for _, tool := range tools {
 		if !*tool.Tool.Annotations.ReadOnlyHint {
 			panic(fmt.Sprintf("tool (%s) must be annotated as read-only", tool.Tool.Name))
@@ -176,6 +394,8 @@ type ToolsetGroup struct {
 	Toolsets     map[string]*Toolset
 	everythingOn bool
 	readOnly     bool
+	// resourceTemplateMiddlewares wrap the handlers of all resource templates
+	resourceTemplateMiddlewares []ResourceTemplateHandlerMiddleware
 }
 
 func NewToolsetGroup(readOnly bool) *ToolsetGroup {
@@ -250,10 +470,63 @@ func (tg *ToolsetGroup) EnableToolset(name string) error {
 	return nil
 }
 
+// UseResourceTemplateMiddleware wraps the handlers of the resource templates registered by RegisterAll.
+func (tg *ToolsetGroup) UseResourceTemplateMiddleware(middleware ResourceTemplateHandlerMiddleware) {
+	tg.resourceTemplateMiddlewares = append(tg.resourceTemplateMiddlewares, middleware)
+}
+
+// FilterTools limits the tools of every toolset to those named in include, unless it is empty,
+// that are not named in exclude. Names that are not tools of any toolset are rejected.
+func (tg *ToolsetGroup) FilterTools(include, exclude []string) error {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+
+	for _, name := range append(append([]string{}, include...), exclude...) {
+		if !tg.hasTool(name) {
+			return NewToolDoesNotExistError(name)
+		}
+	}
+
+	included := make(map[string]bool, len(include))
+	for _, name := range include {
+		included[name] = true
+	}
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+
+	filter := func(name string) bool {
+		return (len(included) == 0 || included[name]) && !excluded[name]
+	}
+	for _, toolset := range tg.Toolsets {
+		toolset.SetToolFilter(filter)
+	}
+	return nil
+}
+
+// SetGHESVersion hides the tools of every toolset that the release of GitHub Enterprise Server
+// does not have. An empty version offers all tools, as for github.com.
+func (tg *ToolsetGroup) SetGHESVersion(version string) {
+	for _, toolset := range tg.Toolsets {
+		toolset.SetGHESVersion(version)
+	}
+}
+
+func (tg *ToolsetGroup) hasTool(name string) bool {
+	for _, toolset := range tg.Toolsets {
+		if toolset.hasTool(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func (tg *ToolsetGroup) RegisterAll(s *server.MCPServer) {
 	for _, toolset := range tg.Toolsets {
 		toolset.RegisterTools(s)
-		toolset.RegisterResourcesTemplates(s)
+		toolset.RegisterResourcesTemplates(s, tg.resourceTemplateMiddlewares...)
 		toolset.RegisterPrompts(s)
 	}
 }
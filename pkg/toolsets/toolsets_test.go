@@ -2,7 +2,11 @@ package toolsets
 
 import (
 	"errors"
+	"slices"
 	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 func TestNewToolsetGroupIsEmptyWithoutEverythingOn(t *testing.T) {
@@ -250,3 +254,158 @@ func TestToolsetGroup_GetToolset(t *testing.T) {
 		t.Errorf("expected error to be ToolsetDoesNotExistError, got %v", err)
 	}
 }
+
+func newTestTool(name string, readOnly bool) ServerTool {
+	return NewServerTool(mcp.NewTool(name, mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: &readOnly})), nil)
+}
+
+func toolNames(tools []server.ServerTool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Tool.Name)
+	}
+	return names
+}
+
+func TestFilterTools(t *testing.T) {
+	newGroup := func() *ToolsetGroup {
+		tsg := NewToolsetGroup(false)
+		tsg.AddToolset(NewToolset("repos", "Repositories").
+			AddReadTools(newTestTool("get_file_contents", true), newTestTool("list_commits", true)).
+			AddWriteTools(newTestTool("delete_file", false)))
+		tsg.AddToolset(NewToolset("issues", "Issues").
+			AddReadTools(newTestTool("get_issue", true)).
+			AddWriteTools(newTestTool("create_issue", false)))
+		return tsg
+	}
+
+	tsg := newGroup()
+	if err := tsg.FilterTools([]string{"get_file_contents", "delete_file", "create_issue"}, []string{"delete_file"}); err != nil {
+		t.Fatalf("Expected no error when filtering tools, got: %v", err)
+	}
+	if got := toolNames(tsg.Toolsets["repos"].GetAvailableTools()); !slices.Equal(got, []string{"get_file_contents"}) {
+		t.Errorf("Expected only get_file_contents to be available in repos, got %v", got)
+	}
+	if got := toolNames(tsg.Toolsets["issues"].GetAvailableTools()); !slices.Equal(got, []string{"create_issue"}) {
+		t.Errorf("Expected only create_issue to be available in issues, got %v", got)
+	}
+	if got := tsg.Toolsets["repos"].GetActiveTools(); got != nil {
+		t.Errorf("Expected no active tools in a disabled toolset, got %v", toolNames(got))
+	}
+	if err := tsg.EnableToolset("repos"); err != nil {
+		t.Fatalf("Expected no error when enabling toolset, got: %v", err)
+	}
+	if got := toolNames(tsg.Toolsets["repos"].GetActiveTools()); !slices.Equal(got, []string{"get_file_contents"}) {
+		t.Errorf("Expected only get_file_contents to be active in repos, got %v", got)
+	}
+
+	// Excluding tools alone keeps all other tools
+	tsg = newGroup()
+	if err := tsg.FilterTools(nil, []string{"delete_file"}); err != nil {
+		t.Fatalf("Expected no error when excluding tools, got: %v", err)
+	}
+	if got := toolNames(tsg.Toolsets["repos"].GetAvailableTools()); !slices.Equal(got, []string{"get_file_contents", "list_commits"}) {
+		t.Errorf("Expected delete_file to be excluded from repos, got %v", got)
+	}
+
+	// Write tools can be named in read-only mode, they are just never offered
+	tsg = NewToolsetGroup(true)
+	tsg.AddToolset(newGroup().Toolsets["issues"])
+	if err := tsg.FilterTools([]string{"create_issue"}, nil); err != nil {
+		t.Errorf("Expected no error when filtering a write tool in read-only mode, got: %v", err)
+	}
+
+	err := newGroup().FilterTools([]string{"get_file_contents"}, []string{"does_not_exist"})
+	if !errors.Is(err, NewToolDoesNotExistError("does_not_exist")) {
+		t.Errorf("Expected ToolDoesNotExistError when filtering an unknown tool, got: %v", err)
+	}
+}
+
+func TestSetGHESVersion(t *testing.T) {
+	tsg := NewToolsetGroup(false)
+	readOnly := true
+	listDiscussions := NewServerTool(mcp.NewTool("list_discussions",
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: &readOnly}),
+		mcp.WithString("owner"),
+		mcp.WithString("orderBy"),
+	), nil)
+	tsg.AddToolset(NewToolset("issues", "Issues").
+		AddReadTools(newTestTool("get_issue", true), newTestTool("list_sub_issues", true).RequireGHESVersion("3.17")).
+		AddWriteTools(newTestTool("assign_copilot_to_issue", false).UnavailableOnGHES()))
+	tsg.AddToolset(NewToolset("discussions", "Discussions").
+		AddReadTools(listDiscussions.RequireGHESVersion("3.6").RequireGHESVersionForArguments("3.13", "orderBy")))
+	issues := tsg.Toolsets["issues"]
+	discussions := tsg.Toolsets["discussions"]
+
+	all := []string{"get_issue", "list_sub_issues", "assign_copilot_to_issue"}
+	if got := toolNames(issues.GetAvailableTools()); !slices.Equal(got, all) {
+		t.Errorf("Expected all tools to be available without a GHES version, got %v", got)
+	}
+
+	tsg.SetGHESVersion("3.16.4")
+	if got := toolNames(issues.GetAvailableTools()); !slices.Equal(got, []string{"get_issue"}) {
+		t.Errorf("Expected only get_issue to be available on GHES 3.16, got %v", got)
+	}
+
+	// Arguments the release does not have are left out of the tool, which is not changed for other releases
+	tsg.SetGHESVersion("3.12.1")
+	tools := discussions.GetAvailableTools()
+	if len(tools) != 1 || tools[0].Tool.InputSchema.Properties["owner"] == nil || tools[0].Tool.InputSchema.Properties["orderBy"] != nil {
+		t.Errorf("Expected list_discussions without orderBy on GHES 3.12, got %v", tools)
+	}
+	if listDiscussions.Tool.InputSchema.Properties["orderBy"] == nil {
+		t.Error("Expected the registered list_discussions to keep orderBy")
+	}
+
+	tsg.SetGHESVersion("3.17.0")
+	if got := toolNames(issues.GetAvailableTools()); !slices.Equal(got, []string{"get_issue", "list_sub_issues"}) {
+		t.Errorf("Expected sub-issues to be available on GHES 3.17, got %v", got)
+	}
+	tools = discussions.GetAvailableTools()
+	if len(tools) != 1 || tools[0].Tool.InputSchema.Properties["orderBy"] == nil {
+		t.Errorf("Expected list_discussions with orderBy on GHES 3.17, got %v", tools)
+	}
+
+	// Unsupported tools can still be named when filtering tools
+	if err := tsg.FilterTools([]string{"assign_copilot_to_issue"}, nil); err != nil {
+		t.Errorf("Expected no error when filtering a tool unsupported by the GHES version, got: %v", err)
+	}
+
+	err := issues.CheckGHESVersion("list_sub_issues", nil, "3.9")
+	if err == nil || err.Error() != "tool list_sub_issues requires GitHub Enterprise Server 3.17 or later, the server runs 3.9" {
+		t.Errorf("Expected an error for a tool newer than the GHES version, got: %v", err)
+	}
+	err = issues.CheckGHESVersion("assign_copilot_to_issue", nil, "3.17.0")
+	if err == nil || err.Error() != "tool assign_copilot_to_issue is not available on GitHub Enterprise Server" {
+		t.Errorf("Expected an error for a tool no GHES version has, got: %v", err)
+	}
+	if err := issues.CheckGHESVersion("assign_copilot_to_issue", nil, ""); err != nil {
+		t.Errorf("Expected no error without a GHES version, got: %v", err)
+	}
+
+	if err := discussions.CheckGHESVersion("list_discussions", map[string]any{"owner": "octo"}, "3.12"); err != nil {
+		t.Errorf("Expected no error for a call without newer arguments, got: %v", err)
+	}
+	err = discussions.CheckGHESVersion("list_discussions", map[string]any{"orderBy": "CREATED_AT"}, "3.12")
+	if err == nil || err.Error() != "argument orderBy of tool list_discussions requires GitHub Enterprise Server 3.13 or later, the server runs 3.12" {
+		t.Errorf("Expected an error for an argument newer than the GHES version, got: %v", err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"3.17", "3.17.0", 0},
+		{"3.9.1", "3.10", -1},
+		{"3.17.0.rc1", "3.17", 0},
+		{"3.18", "3.17.5", 1},
+	}
+	for _, tc := range tests {
+		got := compareVersions(tc.a, tc.b)
+		if (got < 0) != (tc.expected < 0) || (got > 0) != (tc.expected > 0) {
+			t.Errorf("compareVersions(%q, %q) = %d, expected sign of %d", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
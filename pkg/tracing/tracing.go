@@ -0,0 +1,190 @@
+// Package tracing creates OpenTelemetry spans for MCP requests and the GitHub API requests they make.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/github/github-mcp-server"
+
+// Attributes recorded on spans, besides the OpenTelemetry semantic conventions.
+const (
+	MCPMethodKey       = attribute.Key("mcp.method.name")
+	MCPSessionIDKey    = attribute.Key("mcp.session.id")
+	MCPResourceURIKey  = attribute.Key("mcp.resource.uri")
+	ToolNameKey        = attribute.Key("gen_ai.tool.name")
+	GitHubOwnerKey     = attribute.Key("github.owner")
+	GitHubRepoKey      = attribute.Key("github.repo")
+	GitHubAPIKey       = attribute.Key("github.api")
+	GitHubRequestIDKey = attribute.Key("github.request_id")
+)
+
+// Tracer creates the spans of a server.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New creates a Tracer that creates its spans with provider.
+func New(provider trace.TracerProvider) *Tracer {
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// ToolMiddleware creates a span for every tool call. The span is passed on in the context of the
+// tool handler, so that the GitHub API requests the tool makes become its children.
+func (t *Tracer) ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		attrs := []attribute.KeyValue{
+			MCPMethodKey.String(string(mcp.MethodToolsCall)),
+			ToolNameKey.String(request.Params.Name),
+		}
+		attrs = append(attrs, sessionAttributes(ctx)...)
+		arguments := request.GetArguments()
+		if owner, ok := arguments["owner"].(string); ok && owner != "" {
+			attrs = append(attrs, GitHubOwnerKey.String(owner))
+		}
+		if repo, ok := arguments["repo"].(string); ok && repo != "" {
+			attrs = append(attrs, GitHubRepoKey.String(repo))
+		}
+
+		ctx, span := t.tracer.Start(ctx, fmt.Sprintf("%s %s", mcp.MethodToolsCall, request.Params.Name),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		result, err := next(ctx, request)
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case result != nil && result.IsError:
+			span.SetStatus(codes.Error, resultText(result))
+		}
+		return result, err
+	}
+}
+
+// ResourceTemplateMiddleware creates a span for every read of a resource template, such as
+// repo://{owner}/{repo}/contents{/path*}. Like ToolMiddleware, it passes the span on in the context
+// of the handler, so that the GitHub API requests made for the read become its children.
+func (t *Tracer) ResourceTemplateMiddleware(next server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		attrs := []attribute.KeyValue{
+			MCPMethodKey.String(string(mcp.MethodResourcesRead)),
+			MCPResourceURIKey.String(request.Params.URI),
+		}
+		attrs = append(attrs, sessionAttributes(ctx)...)
+
+		ctx, span := t.tracer.Start(ctx, string(mcp.MethodResourcesRead),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		contents, err := next(ctx, request)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return contents, err
+	}
+}
+
+// Transport creates a client span for every request sent through transport, as a child of the
+// span in the request context. classify returns the GitHub API a request targets and its endpoint
+// template, which names the span.
+func (t *Tracer) Transport(transport http.RoundTripper, classify func(req *http.Request) (api, endpoint string)) http.RoundTripper {
+	return &tracingTransport{
+		transport: transport,
+		tracer:    t.tracer,
+		classify:  classify,
+	}
+}
+
+type tracingTransport struct {
+	transport http.RoundTripper
+	tracer    trace.Tracer
+	classify  func(req *http.Request) (api, endpoint string)
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	api, endpoint := t.classify(req)
+
+	attrs := []attribute.KeyValue{
+		GitHubAPIKey.String(api),
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLFull(redactedURL(req)),
+		semconv.ServerAddress(req.URL.Hostname()),
+	}
+	if owner, repo, ok := repoFromPath(req.URL.Path); ok {
+		attrs = append(attrs, GitHubOwnerKey.String(owner), GitHubRepoKey.String(repo))
+	}
+
+	ctx, span := t.tracer.Start(req.Context(), fmt.Sprintf("%s %s", req.Method, endpoint),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	resp, err := t.transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if requestID := resp.Header.Get("X-GitHub-Request-Id"); requestID != "" {
+		span.SetAttributes(GitHubRequestIDKey.String(requestID))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// sessionAttributes identifies the MCP session of the request, if any.
+func sessionAttributes(ctx context.Context) []attribute.KeyValue {
+	if session := server.ClientSessionFromContext(ctx); session != nil && session.SessionID() != "" {
+		return []attribute.KeyValue{MCPSessionIDKey.String(session.SessionID())}
+	}
+	return nil
+}
+
+// repoFromPath extracts the owner and repository from a REST API path such as /repos/{owner}/{repo}/issues.
+func repoFromPath(path string) (string, string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if segment == "repos" && i+2 < len(segments) {
+			return segments[i+1], segments[i+2], true
+		}
+	}
+	return "", "", false
+}
+
+// redactedURL returns the URL of the request without credentials, which may be embedded in user info.
+func redactedURL(req *http.Request) string {
+	u := *req.URL
+	u.User = nil
+	return u.String()
+}
+
+// resultText returns the text of an error result, which describes the error.
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return "tool call failed"
+}
@@ -0,0 +1,125 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracer() (*Tracer, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return New(provider), exporter
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func Test_ToolMiddleware_ParentsAPIRequests(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-GitHub-Request-Id", "ABCD:1234")
+		if r.URL.Path == "/repos/octo/hello-world/git/refs/heads/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	tracer, exporter := newTestTracer()
+	client := &http.Client{Transport: tracer.Transport(http.DefaultTransport, func(_ *http.Request) (string, string) {
+		return "rest", "/repos/{owner}/{repo}/git/refs/{ref}"
+	})}
+
+	handler := tracer.ToolMiddleware(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		for _, ref := range []string{"main", "missing"} {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/repos/octo/hello-world/git/refs/heads/"+ref, nil)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+		}
+		return mcp.NewToolResultError("reference not found"), nil
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "push_files"
+	request.Params.Arguments = map[string]any{"owner": "octo", "repo": "hello-world"}
+	_, err := handler(context.Background(), request)
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	apiSpans, toolSpan := spans[:2], spans[2]
+
+	assert.Equal(t, "tools/call push_files", toolSpan.Name)
+	assert.Equal(t, codes.Error, toolSpan.Status.Code)
+	assert.Equal(t, "reference not found", toolSpan.Status.Description)
+	toolAttrs := attributes(toolSpan)
+	assert.Equal(t, "push_files", toolAttrs[ToolNameKey].AsString())
+	assert.Equal(t, "octo", toolAttrs[GitHubOwnerKey].AsString())
+	assert.Equal(t, "hello-world", toolAttrs[GitHubRepoKey].AsString())
+
+	for _, span := range apiSpans {
+		assert.Equal(t, "GET /repos/{owner}/{repo}/git/refs/{ref}", span.Name)
+		assert.Equal(t, toolSpan.SpanContext.SpanID(), span.Parent.SpanID(), "API requests should be children of the tool call")
+		assert.Equal(t, toolSpan.SpanContext.TraceID(), span.SpanContext.TraceID())
+
+		attrs := attributes(span)
+		assert.Equal(t, "ABCD:1234", attrs[GitHubRequestIDKey].AsString())
+		assert.Equal(t, "rest", attrs[GitHubAPIKey].AsString())
+		assert.Equal(t, "octo", attrs[GitHubOwnerKey].AsString())
+		assert.Equal(t, "hello-world", attrs[GitHubRepoKey].AsString())
+	}
+	assert.Equal(t, int64(200), attributes(apiSpans[0])[semconv.HTTPResponseStatusCodeKey].AsInt64())
+	assert.Equal(t, codes.Unset, apiSpans[0].Status.Code)
+	assert.Equal(t, int64(404), attributes(apiSpans[1])[semconv.HTTPResponseStatusCodeKey].AsInt64())
+	assert.Equal(t, codes.Error, apiSpans[1].Status.Code)
+}
+
+func Test_ResourceTemplateMiddleware(t *testing.T) {
+	tracer, exporter := newTestTracer()
+
+	var handlerSpan trace.SpanContext
+	handler := tracer.ResourceTemplateMiddleware(func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		if request.Params.URI == "repo://octo/hello-world/contents/missing.md" {
+			return nil, errors.New("not found")
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "# Hello"}}, nil
+	})
+
+	read := func(uri string) error {
+		request := mcp.ReadResourceRequest{}
+		request.Params.URI = uri
+		_, err := handler(context.Background(), request)
+		return err
+	}
+	require.NoError(t, read("repo://octo/hello-world/contents/README.md"))
+	require.Error(t, read("repo://octo/hello-world/contents/missing.md"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "resources/read", spans[0].Name)
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, "repo://octo/hello-world/contents/README.md", attributes(spans[0])[MCPResourceURIKey].AsString())
+
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), handlerSpan.SpanID(), "the span should be in the context of the handler")
+}
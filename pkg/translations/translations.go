@@ -10,6 +10,9 @@ import (
 	"github.com/spf13/viper"
 )
 
+// ConfigFile is the file in the working directory that translations are read from and exported to.
+const ConfigFile = "github-mcp-server-config.json"
+
 type TranslationHelperFunc func(key string, defaultValue string) string
 
 func NullTranslationHelper(_ string, defaultValue string) string {
@@ -17,7 +20,17 @@ func NullTranslationHelper(_ string, defaultValue string) string {
 }
 
 func TranslationHelper() (TranslationHelperFunc, func()) {
+	return TranslationHelperWithOverrides(nil)
+}
+
+// TranslationHelperWithOverrides is like TranslationHelper, with overrides taking precedence over the
+// github-mcp-server-config.json file. Values from GITHUB_MCP_ env vars still take precedence over them.
+func TranslationHelperWithOverrides(overrides map[string]string) (TranslationHelperFunc, func()) {
 	var translationKeyMap = map[string]string{}
+	var overrideMap = make(map[string]string, len(overrides))
+	for key, value := range overrides {
+		overrideMap[strings.ToUpper(key)] = value
+	}
 	v := viper.New()
 
 	// Load from JSON file
@@ -44,6 +57,10 @@ func TranslationHelper() (TranslationHelperFunc, func()) {
 				translationKeyMap[key] = value
 				return value
 			}
+			if value, exists := overrideMap[key]; exists {
+				translationKeyMap[key] = value
+				return value
+			}
 
 			v.SetDefault(key, defaultValue)
 			translationKeyMap[key] = v.GetString(key)
@@ -58,7 +75,7 @@ func TranslationHelper() (TranslationHelperFunc, func()) {
 
 // DumpTranslationKeyMap writes the translation map to a json file called github-mcp-server-config.json
 func DumpTranslationKeyMap(translationKeyMap map[string]string) error {
-	file, err := os.Create("github-mcp-server-config.json")
+	file, err := os.Create(ConfigFile)
 	if err != nil {
 		return fmt.Errorf("error creating file: %v", err)
 	}